@@ -0,0 +1,131 @@
+package client
+
+import (
+	"resty.dev/v3"
+
+	"fmt"
+	"time"
+)
+
+const jobFieldsGQL = `id campaign list state createdAt finishedAt`
+
+const jobProgressGQL = jobFieldsGQL + ` startedAt total queued sent failed skipped remaining eta errors error`
+
+// Job is a send job running on the server
+type Job struct {
+	ID         string
+	Campaign   string
+	List       string
+	State      string
+	CreatedAt  string
+	FinishedAt *string
+
+	// Progress, only from Job and WaitJob
+	StartedAt *string
+	Total     int
+	Queued    int
+	Sent      int
+	Failed    int
+	Skipped   int
+	Remaining int
+	ETA       *int // Seconds
+	Errors    []string
+	Error     *string
+}
+
+// Finished is true for done, failed, or cancelled job
+func (j *Job) Finished() bool {
+	return j.FinishedAt != nil
+}
+
+// Job looks up progress of a send job
+func (c *client) Job(id string) (*Job, error) {
+	gql := `query job($id: ID!) { job(id: $id) { ` + jobProgressGQL + ` } }`
+	var data struct{ Job *Job }
+	if err := c.graphQL(gql, map[string]any{"id": id}, &data); err != nil {
+		return nil, err
+	} else if data.Job == nil {
+		return nil, fmt.Errorf("send job %s not found", id)
+	}
+	return data.Job, nil
+}
+
+// WaitJob polls the job every interval until it is finished,
+// and reports its progress after every poll
+func (c *client) WaitJob(id string, interval time.Duration, progress func(*Job)) (*Job, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		job, err := c.Job(id)
+		if err != nil {
+			return nil, err
+		} else if progress != nil {
+			progress(job)
+		}
+
+		if job.Finished() {
+			return job, nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-c.context.Done():
+			return job, c.context.Err()
+		}
+	}
+}
+
+// Jobs lists send jobs on the server
+func (c *client) Jobs() ([]Job, error) {
+	var data struct{ Jobs []Job }
+	err := c.graphQL(`query jobs { jobs { `+jobFieldsGQL+` } }`, nil, &data)
+	return data.Jobs, err
+}
+
+// PauseJob stops dispatching messages of a job until resumed
+func (c *client) PauseJob(id string) (*Job, error) {
+	return c.controlJob("pauseSend", id)
+}
+
+// ResumeJob continues a paused job
+func (c *client) ResumeJob(id string) (*Job, error) {
+	return c.controlJob("resumeSend", id)
+}
+
+// CancelJob drops messages of a job that were not sent yet
+func (c *client) CancelJob(id string) (*Job, error) {
+	return c.controlJob("cancelSend", id)
+}
+
+func (c *client) controlJob(mutation, id string) (*Job, error) {
+	gql := `mutation control($id: ID!) { job: ` + mutation + `(id: $id) { ` + jobFieldsGQL + ` } }`
+	var data struct{ Job *Job }
+	err := c.graphQL(gql, map[string]any{"id": id}, &data)
+	return data.Job, err
+}
+
+// graphQL issues a JSON request, and decodes its data into out
+func (c *client) graphQL(query string, vars map[string]any, out any) error {
+	var output struct {
+		gqlErrorResponse
+		Data any
+	}
+	output.Data = out
+
+	resp, err := resty.New().R().
+		SetContext(c.context).
+		SetBody(map[string]any{"query": query, "variables": vars}).
+		SetResult(&output).
+		Post(c.serverURL)
+
+	if err != nil {
+		return err
+	} else if e := output.Errors; len(e) > 0 {
+		return fmt.Errorf("server error: %s", e[0].Message)
+	} else if resp.IsError() {
+		return fmt.Errorf("server returned %s: %s", resp.Status(), resp.String())
+	}
+
+	return nil
+}
@@ -0,0 +1,175 @@
+package client
+
+import (
+	"github.com/graph-gophers/graphql-go"
+	"github.com/rykov/paperboy/server"
+
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestJobsIntegration(t *testing.T) {
+	h := server.MustSchemaHandler(jobsSchemaSDL, &testJobsResolver{})
+	srv := httptest.NewServer(server.WithMiddleware(h, nil))
+	defer srv.Close()
+
+	cli := New(context.Background(), srv.URL)
+	jobs, err := cli.Jobs()
+	if err != nil {
+		t.Fatalf("client.Jobs failed: %v", err)
+	} else if len(jobs) != 1 || jobs[0].ID != "j1" || jobs[0].State != "running" {
+		t.Fatalf("Unexpected jobs: %+v", jobs)
+	}
+
+	actions := map[string]func(string) (*Job, error){
+		"paused":    cli.PauseJob,
+		"running":   cli.ResumeJob,
+		"cancelled": cli.CancelJob,
+	}
+	for state, action := range actions {
+		if job, err := action("j1"); err != nil {
+			t.Errorf("%s: unexpected error %v", state, err)
+		} else if job.State != state || job.Campaign != "c1" {
+			t.Errorf("%s: unexpected job %+v", state, job)
+		}
+	}
+
+	// Poll until finished
+	var polled []string
+	job, err := cli.WaitJob("j1", time.Millisecond, func(j *Job) {
+		polled = append(polled, fmt.Sprintf("%s %d/%d", j.State, j.Sent, j.Total))
+	})
+	if err != nil {
+		t.Fatalf("client.WaitJob failed: %v", err)
+	} else if !job.Finished() || job.Error == nil || *job.Error != "boom" {
+		t.Errorf("Unexpected finished job %+v", job)
+	} else if e := []string{"running 1/3", "running 2/3", "failed 3/3"}; !slices.Equal(polled, e) {
+		t.Errorf("Expected progress %q, got %q", e, polled)
+	}
+
+	if _, err := cli.Job("missing"); err == nil || err.Error() != "send job missing not found" {
+		t.Errorf("Expected missing job error, got %v", err)
+	}
+
+	if _, err := cli.CancelJob("missing"); err == nil {
+		t.Errorf("Expected server to error, got success")
+	} else if a, e := err.Error(), "server error: not found"; a != e {
+		t.Errorf("Expected error %q, got %q", e, a)
+	}
+}
+
+const jobsSchemaSDL = `
+  schema { query: Query mutation: Mutation }
+  type Query {
+    jobs: [SendJob!]!
+    job(id: ID!): SendJob
+  }
+  type Mutation {
+    pauseSend(id: ID!): SendJob!
+    resumeSend(id: ID!): SendJob!
+    cancelSend(id: ID!): SendJob!
+  }
+  type SendJob {
+    id: ID!
+    campaign: String!
+    list: String!
+    state: String!
+    createdAt: String!
+    startedAt: String
+    finishedAt: String
+    total: Int!
+    queued: Int!
+    sent: Int!
+    failed: Int!
+    skipped: Int!
+    remaining: Int!
+    eta: Int
+    errors: [String!]!
+    error: String
+  }
+`
+
+type testJobsResolver struct {
+	polls int32
+}
+
+type testJob struct {
+	state string
+	sent  int32
+}
+
+func (j *testJob) ID() graphql.ID     { return "j1" }
+func (j *testJob) Campaign() string   { return "c1" }
+func (j *testJob) List() string       { return "l1" }
+func (j *testJob) State() string      { return j.state }
+func (j *testJob) CreatedAt() string  { return "2025-03-01T09:00:00Z" }
+func (j *testJob) StartedAt() *string { return nil }
+func (j *testJob) Total() int32       { return 3 }
+func (j *testJob) Queued() int32      { return 3 }
+func (j *testJob) Sent() int32        { return j.sent }
+func (j *testJob) Failed() int32      { return 0 }
+func (j *testJob) Skipped() int32     { return 0 }
+func (j *testJob) Remaining() int32   { return 3 - j.sent }
+func (j *testJob) ETA() *int32        { return nil }
+func (j *testJob) Errors() []string   { return nil }
+
+func (j *testJob) FinishedAt() *string {
+	if j.state == "failed" {
+		s := "2025-03-01T10:00:00Z"
+		return &s
+	}
+	return nil
+}
+
+func (j *testJob) Error() *string {
+	if j.state == "failed" {
+		s := "boom"
+		return &s
+	}
+	return nil
+}
+
+// Job finishes on the third poll
+func (r *testJobsResolver) Job(args struct{ ID graphql.ID }) *testJob {
+	if args.ID != "j1" {
+		return nil
+	}
+	r.polls++
+	j := &testJob{state: "running", sent: r.polls}
+	if r.polls == 3 {
+		j.state = "failed"
+	}
+	return j
+}
+
+func (r *testJobsResolver) Jobs() []*testJob {
+	return []*testJob{newTestJob("running")}
+}
+
+func (r *testJobsResolver) PauseSend(args struct{ ID graphql.ID }) (*testJob, error) {
+	return controlTestJob(args.ID, "paused")
+}
+
+func (r *testJobsResolver) ResumeSend(args struct{ ID graphql.ID }) (*testJob, error) {
+	return controlTestJob(args.ID, "running")
+}
+
+func (r *testJobsResolver) CancelSend(args struct{ ID graphql.ID }) (*testJob, error) {
+	return controlTestJob(args.ID, "cancelled")
+}
+
+func controlTestJob(id graphql.ID, state string) (*testJob, error) {
+	if id != "j1" {
+		return nil, errors.New("not found")
+	}
+	return newTestJob(state), nil
+}
+
+func newTestJob(state string) *testJob {
+	return &testJob{state: state}
+}
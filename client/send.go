@@ -18,8 +18,8 @@ import (
 )
 
 const sendGQL = `
-  mutation sendCampaign($campaign: String!, $list: String!) {
-    sendCampaign(campaign: $campaign, list: $list)
+  mutation sendCampaign($campaign: String!, $list: String!, $at: String, $window: String) {
+    sendCampaign(campaign: $campaign, list: $list, at: $at, window: $window)
   }
 `
 
@@ -32,7 +32,8 @@ func New(ctx context.Context, url string) *client {
 	return &client{ctx, url}
 }
 
-func (c *client) Send(args SendArgs) error {
+// Send uploads the project, and returns ID of the started send job
+func (c *client) Send(args SendArgs) (string, error) {
 	pr, ct := streamZipToMultipart(args, func(mw *multipart.Writer) error {
 		header := make(textproto.MIMEHeader)
 		header.Set("Content-Type", "application/json")
@@ -47,12 +48,17 @@ func (c *client) Send(args SendArgs) error {
 			"variables": map[string]any{
 				"campaign": args.Campaign,
 				"list":     args.List,
+				"at":       args.At,
+				"window":   args.Window,
 			},
 		})
 	})
 
-	// Capture GraphQL errors
-	var output gqlErrorResponse
+	// Capture job ID and GraphQL errors
+	var output struct {
+		gqlErrorResponse
+		Data struct{ SendCampaign string }
+	}
 
 	// Prepare Resty client and request
 	client := resty.New()
@@ -64,17 +70,17 @@ func (c *client) Send(args SendArgs) error {
 
 	// non‐2xx → treat as error
 	if err != nil {
-		return err
+		return "", err
 	} else if e := output.Errors; len(e) > 0 {
-		return fmt.Errorf("server error: %s", e[0].Message)
+		return "", fmt.Errorf("server error: %s", e[0].Message)
 	} else if resp.IsError() {
-		return fmt.Errorf("server returned %s: %s",
+		return "", fmt.Errorf("server returned %s: %s",
 			resp.Status(),
 			resp.String(),
 		)
 	}
 
-	return nil
+	return output.Data.SendCampaign, nil
 }
 
 // Common GQL error response
@@ -83,6 +89,10 @@ type SendArgs struct {
 	ProjectPath    string
 	Campaign       string
 	List           string
+
+	// Scheduled delivery (optional)
+	At     string
+	Window string
 }
 
 // Common GQL error response
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"github.com/graph-gophers/graphql-go"
 	"github.com/rykov/paperboy/server"
 
 	"archive/zip"
@@ -36,19 +37,28 @@ func TestSendIntegration(t *testing.T) {
 	args := SendArgs{ProjectPath: dir, Campaign: "testCampaign", List: "testList"}
 	args.ProjectIgnores = []string{"*.skip"} // Test ignoring files
 	cli := New(context.Background(), srv.URL)
-	if err := cli.Send(args); err != nil {
+	if id, err := cli.Send(args); err != nil {
 		t.Fatalf("client.Send failed: %v", err)
+	} else if id != "job-testList" {
+		t.Fatalf("Unexpected job ID %q", id)
 	}
 
 	args.List = "testError"
-	if err := cli.Send(args); err == nil {
+	if _, err := cli.Send(args); err == nil {
 		t.Errorf("Expected server to error, got success")
 	} else if a, e := err.Error(), "server error: testError"; a != e {
 		t.Errorf("Expected error %q, got %q", e, a)
 	}
 
+	args.List = "testSchedule"
+	args.At, args.Window = "2025-03-01 09:00", "09:00-17:00"
+	if _, err := cli.Send(args); err != nil {
+		t.Fatalf("client.Send with schedule failed: %v", err)
+	}
+	args.At, args.Window = "", ""
+
 	args.List = "testPanic"
-	if err := cli.Send(args); err == nil {
+	if _, err := cli.Send(args); err == nil {
 		t.Errorf("Expected server to error, got success")
 	} else if a, e := err.Error(), "server error: panic occurred: testPanic"; a != e {
 		t.Errorf("Expected error %q, got %q", e, a)
@@ -62,7 +72,7 @@ const schemaSDL = `
     _schema: String @deprecated(reason: "Not implemented")
   }
   type Mutation {
-    sendCampaign(campaign: String!, list: String!): Boolean!
+    sendCampaign(campaign: String!, list: String!, at: String, window: String): ID!
   }
 `
 
@@ -83,29 +93,35 @@ func (r *testResolver) Schema() *string {
 func (r *testResolver) SendCampaign(ctx context.Context, args struct {
 	Campaign string
 	List     string
-}) (bool, error) {
+	At       *string
+	Window   *string
+}) (graphql.ID, error) {
 	if l := args.List; l == "testError" {
-		return false, errors.New(l)
+		return "", errors.New(l)
 	} else if l == "testPanic" {
 		panic(l)
+	} else if l == "testSchedule" {
+		if args.At == nil || *args.At != "2025-03-01 09:00" || args.Window == nil || *args.Window != "09:00-17:00" {
+			return "", fmt.Errorf("unexpected schedule: %v %v", args.At, args.Window)
+		}
 	}
 
 	f, ok := server.RequestZipFile(ctx)
 	if !ok || f == nil {
-		return false, fmt.Errorf("zip file not found in context")
+		return "", fmt.Errorf("zip file not found in context")
 	}
 	defer f.Close()
 
 	// figure out its size
 	info, err := f.Stat()
 	if err != nil {
-		return false, fmt.Errorf("stat temp file: %w", err)
+		return "", fmt.Errorf("stat temp file: %w", err)
 	}
 
 	// open it as a zip.Reader
 	zr, err := zip.NewReader(f, info.Size())
 	if err != nil {
-		return false, fmt.Errorf("open zip: %w", err)
+		return "", fmt.Errorf("open zip: %w", err)
 	}
 
 	// track which files we saw
@@ -114,20 +130,20 @@ func (r *testResolver) SendCampaign(ctx context.Context, args struct {
 	for _, entry := range zr.File {
 		rc, err := entry.Open()
 		if err != nil {
-			return false, fmt.Errorf("open entry %q: %w", entry.Name, err)
+			return "", fmt.Errorf("open entry %q: %w", entry.Name, err)
 		}
 		data, err := io.ReadAll(rc)
 		rc.Close()
 		if err != nil {
-			return false, fmt.Errorf("read entry %q: %w", entry.Name, err)
+			return "", fmt.Errorf("read entry %q: %w", entry.Name, err)
 		}
 
 		want, exists := expected[entry.Name]
 		if !exists {
-			return false, fmt.Errorf("unexpected file %q in zip", entry.Name)
+			return "", fmt.Errorf("unexpected file %q in zip", entry.Name)
 		}
 		if string(data) != want {
-			return false, fmt.Errorf("file %q contents = %q; want %q", entry.Name, data, want)
+			return "", fmt.Errorf("file %q contents = %q; want %q", entry.Name, data, want)
 		}
 		seen[entry.Name] = true
 	}
@@ -135,9 +151,9 @@ func (r *testResolver) SendCampaign(ctx context.Context, args struct {
 	// make sure we saw them all
 	for name := range expected {
 		if !seen[name] {
-			return false, fmt.Errorf("expected file %q but not found in zip", name)
+			return "", fmt.Errorf("expected file %q but not found in zip", name)
 		}
 	}
 
-	return true, nil
+	return graphql.ID("job-" + args.List), nil
 }
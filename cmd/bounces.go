@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"github.com/rykov/paperboy/config"
+	"github.com/rykov/paperboy/mail"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+func bouncesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bounces",
+		Short: "Process bounces and complaints",
+	}
+
+	var dryRun, verbose bool
+	importCmd := &cobra.Command{
+		Use:   "import [path]",
+		Short: "Suppress addresses of bounces and complaints from a mailbox",
+		Long: "Parses delivery status notifications (RFC 3464) and feedback reports (ARF) in a Maildir, mbox " +
+			"or message file, and suppresses recipients of hard bounces and complaints. Recipients are " +
+			"matched by Message-ID in the delivery ledger (see \"delivery.ledger\" config), or by VERP " +
+			"of the envelope sender (see \"envelopeFrom\" config)",
+		Example: "paperboy bounces import ~/Maildir/.Bounces",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.LoadConfig(cmd.Context())
+			if err != nil {
+				return err
+			}
+			cfg.DryRun = dryRun
+
+			report, err := mail.ImportBounces(cfg, afero.NewOsFs(), args[0])
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if verbose {
+				printBounces(out, report.Bounces)
+			}
+			fmt.Fprintf(out, "Found %d hard bounces, %d soft bounces and %d complaints (%d unrecognized, %d unmatched)\n",
+				report.Count(mail.BounceHard), report.Count(mail.BounceSoft), report.Count(mail.BounceComplaint),
+				report.Unrecognized, report.Unmatched)
+			if !dryRun {
+				fmt.Fprintf(out, "Suppressed %d addresses\n", report.Suppressed)
+			}
+			return nil
+		},
+	}
+
+	importCmd.Flags().BoolVar(&dryRun, "dry-run", false, "report bounces without suppressing")
+	importCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "list every bounce")
+
+	cmd.AddCommand(importCmd)
+	return cmd
+}
+
+func printBounces(out io.Writer, list []mail.Bounce) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "EMAIL\tKIND\tSTATUS\tCAMPAIGN\tDIAGNOSTIC")
+	for _, b := range list {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", b.Email, b.Kind, b.Status, b.Campaign, b.Diagnostic)
+	}
+	w.Flush()
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testBounce = `From: MAILER-DAEMON@mx.example.org
+To: bounces+jane=example.com@example.org
+Content-Type: multipart/report; report-type=delivery-status; boundary="B"
+
+--B
+Content-Type: message/delivery-status
+
+Reporting-MTA: dns; mx.example.org
+
+Final-Recipient: rfc822; jane@example.com
+Action: failed
+Status: 5.1.1
+
+--B--
+`
+
+func TestBouncesImportCmd(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	mbox := filepath.Join(dir, "bounces.mbox")
+	os.WriteFile(mbox, []byte("From MAILER-DAEMON Thu Jan  2 03:04:05 2025\n"+testBounce), 0644)
+
+	steps := []struct {
+		args     []string
+		expected []string
+	}{
+		{[]string{"import", "--dry-run", "-v", mbox}, []string{"jane@example.com  hard", "Found 1 hard bounces"}},
+		{[]string{"import", mbox}, []string{"Found 1 hard bounces", "Suppressed 1 addresses"}},
+		{[]string{"import", mbox}, []string{"Suppressed 0 addresses"}},
+	}
+
+	var out bytes.Buffer
+	for _, s := range steps {
+		out.Reset()
+		cmd := bouncesCmd()
+		cmd.SetOut(&out)
+		cmd.SetArgs(s.args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("bounces %v failed: %v", s.args, err)
+		}
+		for _, e := range s.expected {
+			if !strings.Contains(out.String(), e) {
+				t.Errorf("bounces %v: expected %q in %q", s.args, e, out.String())
+			}
+		}
+	}
+
+	raw, err := os.ReadFile(filepath.Join(dir, "lists", "_suppressed.csv"))
+	if err != nil || !strings.Contains(string(raw), "jane@example.com,bounced") {
+		t.Errorf("Expected bounced suppression: %s %v", raw, err)
+	}
+}
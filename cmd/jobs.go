@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"github.com/rykov/paperboy/client"
+	"github.com/rykov/paperboy/config"
+	"github.com/spf13/cobra"
+
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+func jobsCmd() *cobra.Command {
+	var serverURL string
+
+	// Server of the jobs, local one by default
+	newClient := func(cmd *cobra.Command) (jobsClient, error) {
+		if serverURL == "" {
+			cfg, err := config.LoadConfig(cmd.Context())
+			if err != nil {
+				return nil, err
+			}
+			serverURL = fmt.Sprintf("http://localhost:%d%s", cfg.ServerPort, serverGraphQLPath)
+		}
+		return client.New(cmd.Context(), serverURL), nil
+	}
+
+	cmd := &cobra.Command{
+		Use:     "jobs",
+		Short:   "List send jobs on the server",
+		Example: "paperboy jobs --server http://localhost:8080/graphql",
+		Args:    cobra.NoArgs,
+		Long: `List and control send jobs on the server.
+
+Jobs of a local "paperboy send" are not on the server, and are
+controlled in its terminal instead (Ctrl-Z and Ctrl-C).`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			jobs, err := c.Jobs()
+			if err != nil {
+				return err
+			}
+
+			printJobs(cmd.OutOrStdout(), jobs...)
+			return nil
+		},
+	}
+
+	// Controls for a single job
+	controls := []struct {
+		use, short string
+		action     func(jobsClient, string) (*client.Job, error)
+	}{
+		{"pause", "Pause sending, after in-flight emails", jobsClient.PauseJob},
+		{"resume", "Resume a paused send", jobsClient.ResumeJob},
+		{"cancel", "Cancel sending of remaining emails", jobsClient.CancelJob},
+	}
+
+	for _, ctl := range controls {
+		cmd.AddCommand(&cobra.Command{
+			Use:   ctl.use + " [id]",
+			Short: ctl.short,
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				c, err := newClient(cmd)
+				if err != nil {
+					return err
+				}
+
+				job, err := ctl.action(c, args[0])
+				if err != nil {
+					return err
+				}
+
+				printJobs(cmd.OutOrStdout(), *job)
+				return nil
+			},
+		})
+	}
+
+	// Server to specify remote server
+	cmd.PersistentFlags().StringVar(&serverURL, "server", "", "URL of server (default: local server)")
+
+	return cmd
+}
+
+// Job controls of the API client
+type jobsClient interface {
+	Jobs() ([]client.Job, error)
+	PauseJob(id string) (*client.Job, error)
+	ResumeJob(id string) (*client.Job, error)
+	CancelJob(id string) (*client.Job, error)
+}
+
+func printJobs(out io.Writer, jobs ...client.Job) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tCAMPAIGN\tLIST\tSTATE\tCREATED")
+	for _, j := range jobs {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", j.ID, j.Campaign, j.List, j.State, j.CreatedAt)
+	}
+	w.Flush()
+}
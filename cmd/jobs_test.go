@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"github.com/rykov/paperboy/config"
+	"github.com/rykov/paperboy/mail/send"
+	"github.com/rykov/paperboy/server"
+
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestJobsCmd(t *testing.T) {
+	srv := httptest.NewServer(server.GraphQLHandler(&config.AConfig{}))
+	defer srv.Close()
+
+	job := send.NewJob("the-announcement", "customers")
+	steps := []struct {
+		args  []string
+		state string
+	}{
+		{nil, send.JobRunning},
+		{[]string{"pause", job.ID}, send.JobPaused},
+		{[]string{"resume", job.ID}, send.JobRunning},
+		{[]string{"cancel", job.ID}, send.JobCancelled},
+	}
+
+	for _, s := range steps {
+		var out bytes.Buffer
+		cmd := jobsCmd()
+		cmd.SetOut(&out)
+		cmd.SetArgs(append(s.args, "--server", srv.URL))
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("jobs %v failed: %v", s.args, err)
+		}
+
+		if a := job.State(); a != s.state {
+			t.Errorf("jobs %v: expected %s, got %s", s.args, s.state, a)
+		}
+
+		var line string
+		for _, l := range strings.Split(out.String(), "\n") {
+			if strings.HasPrefix(l, job.ID) {
+				line = l
+			}
+		}
+		if f := strings.Fields(line); len(f) != 5 || f[1] != "the-announcement" || f[3] != s.state {
+			t.Errorf("jobs %v: unexpected output %q", s.args, out.String())
+		}
+	}
+
+	cmd := jobsCmd()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetArgs([]string{"resume", job.ID, "--server", srv.URL})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "is cancelled") {
+		t.Errorf("Expected cancelled job error, got %v", err)
+	}
+}
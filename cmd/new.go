@@ -44,6 +44,10 @@ unsubscribeURL = "https://example.org/unsubscribe/{Recipient.Email}"
 # SMTP Server
 [smtp]
   url = "smtp://smtp.example.org"
+
+# Audit trail of every delivery (ledger = "" turns it off)
+# [delivery]
+#   ledger = ".paperboy/ledger.jsonl"
 `
 
 	newProjectBanner = `Congratulations! Your new project is ready in {{ .Path }}
@@ -22,7 +22,8 @@ const (
 var previewTestMode = false
 
 func previewCmd() *cobra.Command {
-	return &cobra.Command{
+	var watch bool
+	cmd := &cobra.Command{
 		Use:   "preview [content] [list]",
 		Short: "Preview campaign in browser",
 		Args:  cobra.ExactArgs(2),
@@ -33,7 +34,7 @@ func previewCmd() *cobra.Command {
 			}
 
 			// Start server, notifies channel when listening
-			return startAPIServer(cfg, func(mux *http.ServeMux, serverReady chan bool) error {
+			return startAPIServer(cfg, watch, func(mux *http.ServeMux, serverReady chan bool) error {
 
 				// Wait for server and open preview
 				go func() {
@@ -46,6 +47,9 @@ func previewCmd() *cobra.Command {
 			})
 		},
 	}
+
+	cmd.Flags().BoolVarP(&watch, "watch", "w", true, "re-render preview when project files change")
+	return cmd
 }
 
 func openPreview(cmd *cobra.Command, cfg *config.AConfig, content, list string) {
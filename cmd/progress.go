@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"github.com/rykov/paperboy/client"
+	"github.com/rykov/paperboy/mail/send"
+
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	// Polling of server's send jobs
+	jobPollInterval = 2 * time.Second
+
+	// Width of progress bar in characters
+	progressWidth = 30
+)
+
+// progressBar redraws a job's progress on a single line
+type progressBar struct {
+	out  io.Writer
+	last string
+}
+
+func newProgressBar(out io.Writer) *progressBar {
+	return &progressBar{out: out}
+}
+
+func (p *progressBar) update(j *client.Job) {
+	line := renderProgress(j)
+	if line == p.last {
+		return
+	}
+
+	// Pad to erase longer previous line
+	pad := max(len(p.last)-len(line), 0)
+	fmt.Fprintf(p.out, "\r%s%s", line, strings.Repeat(" ", pad))
+	p.last = line
+}
+
+func renderProgress(j *client.Job) string {
+	total := max(j.Total, j.Queued+j.Skipped, 1)
+	done := min(j.Sent+j.Failed+j.Skipped, total)
+	filled := progressWidth * done / total
+
+	var out strings.Builder
+	bar := strings.Repeat("#", filled) + strings.Repeat("-", progressWidth-filled)
+	fmt.Fprintf(&out, "[%s] %3d%% %d/%d sent", bar, 100*done/total, j.Sent, j.Total)
+	if j.Failed > 0 {
+		fmt.Fprintf(&out, ", %d failed", j.Failed)
+	}
+	if j.Skipped > 0 {
+		fmt.Fprintf(&out, ", %d skipped", j.Skipped)
+	}
+	if j.ETA != nil && j.State == send.JobRunning {
+		fmt.Fprintf(&out, ", ETA %s", time.Duration(*j.ETA)*time.Second)
+	}
+	if j.State != send.JobRunning {
+		fmt.Fprintf(&out, " (%s)", j.State)
+	}
+	return out.String()
+}
+
+// jobError is the outcome of a finished job
+func jobError(j *client.Job) error {
+	switch j.State {
+	case send.JobFailed:
+		if j.Error != nil {
+			return errors.New(*j.Error)
+		}
+		return fmt.Errorf("send job %s failed", j.ID)
+	case send.JobCancelled:
+		return fmt.Errorf("send job %s was cancelled", j.ID)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"github.com/rykov/paperboy/client"
+
+	"bytes"
+	"testing"
+)
+
+func TestRenderProgress(t *testing.T) {
+	eta, msg := 90, "boom"
+	cases := []struct {
+		job      client.Job
+		expected string
+	}{
+		{client.Job{State: "running", Total: 10}, "[------------------------------]   0% 0/10 sent"},
+		{client.Job{State: "running", Total: 10, Sent: 4, Failed: 1, ETA: &eta}, "[###############---------------]  50% 4/10 sent, 1 failed, ETA 1m30s"},
+		{client.Job{State: "paused", Total: 4, Sent: 1, Skipped: 1, ETA: &eta}, "[###############---------------]  50% 1/4 sent, 1 skipped (paused)"},
+		{client.Job{State: "failed", Total: 2, Sent: 2, Error: &msg}, "[##############################] 100% 2/2 sent (failed)"},
+		{client.Job{State: "done"}, "[------------------------------]   0% 0/0 sent (done)"},
+	}
+
+	for _, c := range cases {
+		if a := renderProgress(&c.job); a != c.expected {
+			t.Errorf("Expected %q, got %q", c.expected, a)
+		}
+	}
+}
+
+func TestProgressBar(t *testing.T) {
+	var out bytes.Buffer
+	bar := newProgressBar(&out)
+	bar.update(&client.Job{State: "running", Total: 100, Sent: 10, Failed: 10})
+	bar.update(&client.Job{State: "running", Total: 100, Sent: 10, Failed: 10})
+	bar.update(&client.Job{State: "running", Total: 100, Sent: 50})
+
+	first := "\r[######------------------------]  20% 10/100 sent, 10 failed"
+	second := "\r[###############---------------]  50% 50/100 sent           "
+	if a := out.String(); a != first+second {
+		t.Errorf("Unexpected output %q", a)
+	}
+}
+
+func TestJobError(t *testing.T) {
+	msg := "failed to deliver 1 of 2 emails"
+	cases := []struct {
+		job      client.Job
+		expected string
+	}{
+		{client.Job{ID: "j1", State: "done"}, ""},
+		{client.Job{ID: "j1", State: "failed", Error: &msg}, msg},
+		{client.Job{ID: "j1", State: "failed"}, "send job j1 failed"},
+		{client.Job{ID: "j1", State: "cancelled"}, "send job j1 was cancelled"},
+	}
+
+	for _, c := range cases {
+		var a string
+		if err := jobError(&c.job); err != nil {
+			a = err.Error()
+		}
+		if a != c.expected {
+			t.Errorf("Expected %q, got %q", c.expected, a)
+		}
+	}
+}
@@ -18,6 +18,10 @@ func New(build config.BuildInfo) *cobra.Command {
 	rootCmd.AddCommand(newCmd())
 	rootCmd.AddCommand(initCmd())
 	rootCmd.AddCommand(sendCmd())
+	rootCmd.AddCommand(scheduleCmd())
+	rootCmd.AddCommand(jobsCmd())
+	rootCmd.AddCommand(suppressCmd())
+	rootCmd.AddCommand(bouncesCmd())
 	rootCmd.AddCommand(serverCmd())
 	rootCmd.AddCommand(versionCmd())
 	rootCmd.AddCommand(previewCmd())
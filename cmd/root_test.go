@@ -35,7 +35,7 @@ func TestNewSubcommands(t *testing.T) {
 	build := config.BuildInfo{Version: "test", BuildDate: "test"}
 	cmd := New(build)
 
-	expectedCommands := []string{"new", "init", "send", "server", "version", "preview"}
+	expectedCommands := []string{"new", "init", "send", "schedule", "jobs", "suppress", "bounces", "server", "version", "preview"}
 
 	for _, expectedCmd := range expectedCommands {
 		found := false
@@ -109,7 +109,7 @@ func TestCommandStructure(t *testing.T) {
 		commandNames[subCmd.Name()] = true
 	}
 
-	requiredCommands := []string{"new", "init", "send", "server", "version", "preview"}
+	requiredCommands := []string{"new", "init", "send", "schedule", "jobs", "suppress", "bounces", "server", "version", "preview"}
 	for _, required := range requiredCommands {
 		if !commandNames[required] {
 			t.Errorf("Missing required command: %s", required)
@@ -0,0 +1,15 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// Like "send", but requires a delivery time
+func scheduleCmd() *cobra.Command {
+	cmd := sendCmd()
+	cmd.Use = "schedule [content] [list]"
+	cmd.Short = "Schedule campaign delivery to recipients"
+	cmd.Example = `paperboy schedule the-announcement customers --at "2025-03-01 09:00" --window 09:00-17:00`
+	cmd.MarkFlagRequired("at")
+	return cmd
+}
@@ -4,11 +4,18 @@ import (
 	"github.com/rykov/paperboy/client"
 	"github.com/rykov/paperboy/config"
 	"github.com/rykov/paperboy/mail"
+	"github.com/rykov/paperboy/mail/send"
 	"github.com/spf13/cobra"
+
+	"context"
+	"fmt"
+	"io"
 )
 
 func sendCmd() *cobra.Command {
 	var serverURL string
+	var resume bool
+	var at, window string
 
 	cmd := &cobra.Command{
 		Use:     "send [content] [list]",
@@ -23,21 +30,83 @@ func sendCmd() *cobra.Command {
 				return err
 			}
 
-			if u := serverURL; u == "" {
-				return mail.LoadAndSendCampaign(cfg, args[0], args[1])
-			} else {
-				return client.New(cmd.Context(), u).Send(client.SendArgs{
-					ProjectPath:    ".", // TODO: configurable
-					ProjectIgnores: cfg.ClientIgnores,
-					Campaign:       args[0],
-					List:           args[1],
-				})
+			// Continue a previously interrupted delivery
+			if resume {
+				if serverURL != "" {
+					return newUserError("--resume is not supported with --server")
+				}
+				cfg.Delivery.Resume = true
+			}
+
+			// Hold delivery until scheduled time and window
+			if at != "" {
+				cfg.Delivery.At = at
+			}
+			if window != "" {
+				cfg.Delivery.Window = window
+			}
+			dc := cfg.Delivery
+			if _, err := send.ParseSchedule(dc.At, dc.Window, dc.Timezone); err != nil {
+				return newUserError("%s", err)
+			}
+
+			if serverURL == "" {
+				return sendLocal(cmd.Context(), cmd.OutOrStdout(), cfg, args[0], args[1])
+			}
+
+			// Start sending on server, and watch the job
+			c := client.New(cmd.Context(), serverURL)
+			id, err := c.Send(client.SendArgs{
+				ProjectPath:    ".", // TODO: configurable
+				ProjectIgnores: cfg.ClientIgnores,
+				Campaign:       args[0],
+				List:           args[1],
+				At:             at,
+				Window:         window,
+			})
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "Started send job %s\n", id)
+			job, err := c.WaitJob(id, jobPollInterval, newProgressBar(out).update)
+			fmt.Fprintln(out)
+			if err != nil {
+				return fmt.Errorf("stopped watching send job %s (see \"paperboy jobs\"): %w", id, err)
 			}
+
+			return jobError(job)
 		},
 	}
 
 	// Server to specify remote server
 	cmd.Flags().StringVar(&serverURL, "server", "", "URL of server")
 
+	// Resume delivery from the "disk" queue state
+	cmd.Flags().BoolVar(&resume, "resume", false, "skip recipients delivered by a previous run")
+
+	// Scheduled delivery
+	cmd.Flags().StringVar(&at, "at", "", `hold delivery until time (e.g. "2025-03-01 09:00")`)
+	cmd.Flags().StringVar(&window, "window", "", `deliver daily within window (e.g. "09:00-17:00")`)
+
 	return cmd
 }
+
+// Send from this process, where the job is controlled by signals,
+// since "paperboy jobs" only finds jobs of the server. Cancelling
+// ctx cancels the job, which lets in-flight emails finish.
+func sendLocal(ctx context.Context, out io.Writer, cfg *config.AConfig, content, list string) (err error) {
+	job := send.NewJob(content, list)
+	defer func() { job.Done(err) }()
+
+	stop := controlJob(ctx, out, job)
+	defer stop()
+
+	fmt.Fprintf(out, "Starting send job %s (%s)\n", job.ID, jobSignalsHelp)
+	cfg = cfg.WithContext(send.WithJob(context.WithoutCancel(ctx), job))
+	if err = mail.LoadAndSendCampaign(cfg, content, list); err != nil && ctx.Err() != nil {
+		return fmt.Errorf("%w: %w", ctx.Err(), err)
+	}
+	return err
+}
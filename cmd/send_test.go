@@ -1,8 +1,12 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
 	"testing"
+	"time"
 
+	"github.com/rykov/paperboy/mail/send"
 	"github.com/spf13/cobra"
 )
 
@@ -120,3 +124,70 @@ func TestSendCmdValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestSendCmdResumeFlag(t *testing.T) {
+	cmd := sendCmd()
+
+	resumeFlag := cmd.Flags().Lookup("resume")
+	if resumeFlag == nil {
+		t.Fatal("Expected --resume flag to be present")
+	}
+
+	if resumeFlag.DefValue != "false" {
+		t.Errorf("Expected resume flag default value to be false, got %s", resumeFlag.DefValue)
+	}
+}
+
+func TestSendCmdScheduleFlags(t *testing.T) {
+	cmd := sendCmd()
+	for _, name := range []string{"at", "window"} {
+		if f := cmd.Flags().Lookup(name); f == nil {
+			t.Errorf("Expected --%s flag to be present", name)
+		} else if f.DefValue != "" {
+			t.Errorf("Expected --%s default to be empty, got %s", name, f.DefValue)
+		}
+	}
+}
+
+func TestScheduleCmd(t *testing.T) {
+	cmd := scheduleCmd()
+
+	if cmd.Use != "schedule [content] [list]" {
+		t.Errorf("Unexpected Use: %s", cmd.Use)
+	}
+
+	f := cmd.Flags().Lookup("at")
+	if f == nil {
+		t.Fatal("Expected --at flag to be present")
+	} else if req := f.Annotations[cobra.BashCompOneRequiredFlag]; len(req) == 0 || req[0] != "true" {
+		t.Errorf("Expected --at flag to be required")
+	}
+}
+
+func TestControlJob(t *testing.T) {
+	var out bytes.Buffer
+	job := send.NewJob("c1", "list")
+	defer job.Done(nil)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	stop := controlJob(ctx, &out, job)
+	defer stop()
+
+	togglePause(&out, job)
+	if s := job.State(); s != send.JobPaused {
+		t.Errorf("Expected paused job, got %s", s)
+	}
+	togglePause(&out, job)
+	if s := job.State(); s != send.JobRunning {
+		t.Errorf("Expected resumed job, got %s", s)
+	}
+
+	// Cancelled context cancels the job
+	cancel()
+	for deadline := time.Now().Add(time.Second); job.State() != send.JobCancelled; {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected cancelled job, got %s", job.State())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"io/fs"
 	"net"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -21,7 +22,8 @@ const (
 )
 
 func serverCmd() *cobra.Command {
-	return &cobra.Command{
+	var watch bool
+	cmd := &cobra.Command{
 		Use:   "server",
 		Short: "Launch a preview server for emails",
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -29,21 +31,36 @@ func serverCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			return startAPIServer(cfg, nil)
+			return startAPIServer(cfg, watch, nil)
 		},
 	}
+
+	cmd.Flags().BoolVarP(&watch, "watch", "w", true, "reload previews when project files change")
+	return cmd
 }
 
 // Function is called before booting the server to configure
 // additional routes for mux, and to provide "ready" hooks
 type configFunc func(*http.ServeMux, chan bool) error
 
-func startAPIServer(cfg *config.AConfig, configFn configFunc) error {
+func startAPIServer(cfg *config.AConfig, watch bool, configFn configFunc) error {
 	// Simple router, for now
 	mux := http.NewServeMux()
 
 	// GraphQL API is handled via API
-	mux.Handle(serverGraphQLPath, server.GraphQLHandler(cfg))
+	if !watch {
+		mux.Handle(serverGraphQLPath, server.GraphQLHandler(cfg))
+	} else if h, err := server.LiveGraphQLHandler(cfg); err != nil {
+		return fmt.Errorf("failed to watch project: %w", err)
+	} else {
+		fmt.Printf("Watching for changes in %s\n", strings.Join(cfg.AppFs.ProjectDirs(), ", "))
+		mux.Handle(serverGraphQLPath, h)
+	}
+
+	// Unsubscribe page for links with signed tokens
+	if cfg.UnsubscribeSecret != "" {
+		mux.Handle(server.UnsubscribePath, server.UnsubscribeHandler(cfg))
+	}
 
 	// Append additional routes (e.g. preview)
 	var ready chan bool = nil
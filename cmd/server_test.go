@@ -30,6 +30,10 @@ func TestServerCmd(t *testing.T) {
 	if cmd.Run != nil {
 		t.Error("Run function should be nil when RunE is set")
 	}
+
+	if f := cmd.Flags().Lookup("watch"); f == nil || f.DefValue != "true" {
+		t.Errorf("Expected --watch flag enabled by default, got %+v", f)
+	}
 }
 
 func TestServerConstants(t *testing.T) {
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"github.com/rykov/paperboy/mail/send"
+
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+)
+
+// controlJob cancels a local send job, once ctx is cancelled (e.g. on
+// Ctrl-C), and pauses or resumes it on pauseSignal, since the job is
+// not known to "paperboy jobs"
+func controlJob(ctx context.Context, out io.Writer, job *send.Job) (stop func()) {
+	ch := make(chan os.Signal, 1)
+	if pauseSignal != nil {
+		signal.Notify(ch, pauseSignal)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				if job.Cancel() == nil {
+					fmt.Fprintf(out, "\nCancelling send job %s, after in-flight emails\n", job.ID)
+				}
+				ctx = context.Background() // Once
+			case <-ch:
+				togglePause(out, job)
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(ch)
+		close(done)
+	}
+}
+
+// Pause a running job, or resume a paused one
+func togglePause(out io.Writer, job *send.Job) {
+	if job.Pause() == nil {
+		fmt.Fprintf(out, "\nPaused send job %s\n", job.ID)
+	} else if job.Resume() == nil {
+		fmt.Fprintf(out, "\nResumed send job %s\n", job.ID)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package cmd
+
+import "os"
+
+// No pause signal outside of Unix
+var pauseSignal os.Signal
+
+const jobSignalsHelp = "Ctrl-C to cancel"
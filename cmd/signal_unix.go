@@ -0,0 +1,13 @@
+//go:build unix
+
+package cmd
+
+import (
+	"os"
+	"syscall"
+)
+
+// Ctrl-Z pauses and resumes, instead of suspending
+var pauseSignal os.Signal = syscall.SIGTSTP
+
+const jobSignalsHelp = "Ctrl-Z to pause or resume, Ctrl-C to cancel"
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"github.com/rykov/paperboy/config"
+	"github.com/rykov/paperboy/mail"
+	"github.com/spf13/cobra"
+
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+func suppressCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "suppress",
+		Short: "Manage addresses excluded from all sends",
+		Long:  "Unsubscribed, bounced or complained addresses are never sent to (see \"suppressionList\" config)",
+	}
+
+	var addReason string
+	addCmd := &cobra.Command{
+		Use:     "add [email]...",
+		Short:   "Suppress one or more addresses",
+		Example: "paperboy suppress add --reason unsubscribed jane@example.com",
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.LoadConfig(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			added, err := mail.Suppress(cfg, addReason, args...)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Suppressed %d addresses\n", added)
+			return nil
+		},
+	}
+
+	removeCmd := &cobra.Command{
+		Use:   "remove [email]...",
+		Short: "Allow sending to suppressed addresses again",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.LoadConfig(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			removed := 0
+			err = mail.UpdateSuppressions(cfg, func(s *mail.Suppressions) error {
+				for _, e := range args {
+					if s.Remove(e) {
+						removed++
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed %d addresses\n", removed)
+			return nil
+		},
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List suppressed addresses",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.LoadConfig(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			s, err := mail.LoadSuppressions(cfg)
+			if err != nil {
+				return err
+			}
+
+			printSuppressions(cmd.OutOrStdout(), s.All())
+			return nil
+		},
+	}
+
+	var importReason string
+	importCmd := &cobra.Command{
+		Use:     "import [file]",
+		Short:   "Suppress addresses from a CSV file",
+		Long:    "Imports a CSV file with an \"email\" column, and optional \"reason\" and \"time\" (RFC 3339) columns",
+		Example: "paperboy suppress import --reason bounced bounces.csv",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.LoadConfig(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			file, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+
+			added := 0
+			err = mail.UpdateSuppressions(cfg, func(s *mail.Suppressions) error {
+				added, err = s.Import(file, importReason)
+				if err != nil {
+					return fmt.Errorf("failed to import %s: %w", args[0], err)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Suppressed %d addresses\n", added)
+			return nil
+		},
+	}
+
+	addCmd.Flags().StringVar(&addReason, "reason", mail.SuppressManual, "reason for suppression (e.g. unsubscribed, bounced, complained)")
+	importCmd.Flags().StringVar(&importReason, "reason", mail.SuppressManual, "reason for rows without one")
+
+	cmd.AddCommand(addCmd, removeCmd, listCmd, importCmd)
+	return cmd
+}
+
+func printSuppressions(out io.Writer, list []mail.Suppression) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "EMAIL\tREASON\tTIME")
+	for _, s := range list {
+		var t string
+		if !s.Time.IsZero() {
+			t = s.Time.Local().Format(time.DateTime)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", s.Email, s.Reason, t)
+	}
+	w.Flush()
+}
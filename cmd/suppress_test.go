@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSuppressCmd(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	importFile := filepath.Join(dir, "bounces.csv")
+	os.WriteFile(importFile, []byte("email,reason\nc@example.com,\nd@example.com,complained\n"), 0644)
+
+	steps := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"add", "a@example.com", "b@example.com", "--reason", "unsubscribed"}, "Suppressed 2 addresses"},
+		{[]string{"import", importFile, "--reason", "bounced"}, "Suppressed 2 addresses"},
+		{[]string{"remove", "B@example.com"}, "Removed 1 addresses"},
+		{[]string{"list"}, "EMAIL"},
+	}
+
+	var out bytes.Buffer
+	for _, s := range steps {
+		out.Reset()
+		cmd := suppressCmd()
+		cmd.SetOut(&out)
+		cmd.SetArgs(s.args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("suppress %v failed: %v", s.args, err)
+		} else if !strings.Contains(out.String(), s.expected) {
+			t.Errorf("suppress %v: unexpected output %q", s.args, out.String())
+		}
+	}
+
+	// Listing of the remaining addresses
+	list := out.String()
+	for _, l := range []string{"a@example.com  unsubscribed", "c@example.com  bounced", "d@example.com  complained"} {
+		if !strings.Contains(list, l) {
+			t.Errorf("Expected %q in list:\n%s", l, list)
+		}
+	}
+	if strings.Contains(list, "b@example.com") {
+		t.Errorf("Expected b@example.com to be removed:\n%s", list)
+	}
+
+	// Stored within the project's lists
+	if _, err := os.Stat(filepath.Join(dir, "lists", "_suppressed.csv")); err != nil {
+		t.Errorf("Expected suppression list: %v", err)
+	}
+}
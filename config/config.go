@@ -45,10 +45,28 @@ type ConfigFile struct {
 	Theme string
 	From  string
 
+	// SMTP sender (MAIL FROM) template, when not "From",
+	// e.g. "bounces+{{ .Recipient.Email | verp }}@example.org"
+	EnvelopeFrom string
+
 	// CAN-SPAM
 	Address        string
 	UnsubscribeURL string
 
+	// Optional "mailto:" template for List-Unsubscribe header
+	UnsubscribeMailto string
+
+	// Key signing "{token}" of unsubscribe templates, which enables
+	// the server's unsubscribe page (e.g. ".../unsubscribe/{token}")
+	UnsubscribeSecret string
+
+	// Unsubscribed, bounced or complained addresses
+	// excluded from all sends (default: lists/_suppressed.csv)
+	SuppressionList string
+
+	// Custom headers of all emails (templates)
+	Headers map[string]string
+
 	// Delivery
 	SMTP   send.SMTPConfig
 	DryRun bool
@@ -69,6 +87,11 @@ type ConfigFile struct {
 	// Delivery
 	SendRate float32
 	Workers  int
+	Delivery DeliveryConfig
+
+	// Rendering ahead of delivery, where
+	// zero is one worker per CPU
+	RenderWorkers int
 
 	// Client/Server
 	ClientIgnores []string
@@ -76,6 +99,45 @@ type ConfigFile struct {
 	ServerPort    uint
 }
 
+// Configuration for delivery queue
+type DeliveryConfig struct {
+	Queue    string // "memory" or "disk"
+	QueueDir string
+
+	// Skip recipients delivered by a previous
+	// run of the "disk" queue (see "send --resume")
+	Resume bool
+
+	// Audit trail of deliveries (.jsonl or .csv),
+	// which is turned off by an empty path
+	Ledger string
+
+	// Filesystem of queue state and ledger, when
+	// project is read-only (e.g. uploaded to server)
+	Fs afero.Fs `mapstructure:"-"`
+
+	// Retries of temporary failures
+	Retry send.RetryConfig
+
+	// Rate and workers by recipient domain, where unset
+	// limits fall back to global "sendRate" and "workers"
+	Domains map[string]send.DomainConfig `mapstructure:"-"`
+
+	// Slow down on remote throttling
+	Adaptive send.AdaptiveConfig
+
+	// Messages per send, and per SMTP session (0 is unlimited)
+	BatchSize    int
+	SessionLimit int
+
+	// Scheduled delivery time and daily window (see "send --at/--window"),
+	// in recipient's timezone column, or default timezone (local if blank)
+	At             string
+	Window         string
+	Timezone       string
+	TimezoneColumn string
+}
+
 // Configuration for CSV parsing
 type CSVConfig struct {
 	Separator string
@@ -117,7 +179,12 @@ func LoadConfigFs(ctx context.Context, fs afero.Fs) (*AConfig, error) {
 		}
 	}
 
-	err := viperConfig.Unmarshal(&cfg.ConfigFile)
+	if err := viperConfig.Unmarshal(&cfg.ConfigFile); err != nil {
+		return cfg, err
+	}
+
+	// Unmarshal splits keys on dots, which domains contain
+	err := viperConfig.UnmarshalKey("delivery.domains", &cfg.Delivery.Domains)
 	return cfg, err
 }
 
@@ -146,7 +213,10 @@ func newViperConfig(fs afero.Fs) *viper.Viper {
 	v.SetDefault("smtp.pass", "")
 	v.SetDefault("smtp.tls.InsecureSkipVerify", false)
 	v.SetDefault("smtp.tls.MinVersion", "1.2")
+	v.SetDefault("smtp.maxFailures", 3)
+	v.SetDefault("smtp.cooldown", "1m")
 	v.SetDefault("dryRun", false)
+	v.SetDefault("envelopeFrom", "")
 
 	// Defaults (Dirs)
 	v.SetDefault("assetDir", "assets")
@@ -154,10 +224,38 @@ func newViperConfig(fs afero.Fs) *viper.Viper {
 	v.SetDefault("layoutDir", "layouts")
 	v.SetDefault("themeDir", "themes")
 	v.SetDefault("listDir", "lists")
+	v.SetDefault("suppressionList", "")
+	v.SetDefault("unsubscribeMailto", "")
+	v.SetDefault("unsubscribeSecret", "")
 
 	// Delivery workers/rate
 	v.SetDefault("sendRate", 1)
 	v.SetDefault("workers", 3)
+	v.SetDefault("renderWorkers", 0)
+
+	// Delivery queue and its state
+	v.SetDefault("delivery.queue", "memory")
+	v.SetDefault("delivery.queueDir", ".paperboy/queue")
+	v.SetDefault("delivery.resume", false)
+	v.SetDefault("delivery.ledger", ".paperboy/ledger.jsonl")
+	v.SetDefault("delivery.batchSize", 10)
+	v.SetDefault("delivery.sessionLimit", 0)
+
+	// Scheduled delivery
+	v.SetDefault("delivery.at", "")
+	v.SetDefault("delivery.window", "")
+	v.SetDefault("delivery.timezone", "")
+	v.SetDefault("delivery.timezoneColumn", "timezone")
+
+	// Retry policy for temporary failures
+	v.SetDefault("delivery.retry.maxAttempts", 3)
+	v.SetDefault("delivery.retry.initialInterval", "30s")
+	v.SetDefault("delivery.retry.maxInterval", "10m")
+	v.SetDefault("delivery.retry.multiplier", 2)
+
+	// Adaptive rate on remote throttling
+	v.SetDefault("delivery.adaptive.enabled", true)
+	v.SetDefault("delivery.adaptive.minRate", 0.1)
 
 	// Defaults (recipients)
 	v.SetDefault("csv.separator", ",")
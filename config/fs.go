@@ -27,6 +27,14 @@ func (f *Fs) ListPath(name string) string {
 	return filepath.Join(f.Config.ListDir, name)
 }
 
+// SuppressionPath of the project's suppressed addresses
+func (f *Fs) SuppressionPath() string {
+	if p := f.Config.SuppressionList; p != "" {
+		return p
+	}
+	return f.ListPath("_suppressed.csv")
+}
+
 func (f *Fs) LayoutPath(name string) string {
 	p := []string{filepath.Join(f.Config.LayoutDir, name)}
 	if t := f.Config.Theme; t != "" {
@@ -39,6 +47,12 @@ func (f *Fs) AssetPath(name string) string {
 	return filepath.Join(f.Config.AssetDir, name)
 }
 
+// ProjectDirs with sources of rendered emails (e.g. for watching)
+func (f *Fs) ProjectDirs() []string {
+	c := f.Config
+	return []string{c.ContentDir, c.LayoutDir, c.ThemeDir, c.AssetDir, c.ListDir}
+}
+
 func (f *Fs) FindContentPath(name string) string {
 	paths := []string{f.ContentPath(name)}
 	return f.findFileWithExtension(paths, contentExts)
@@ -76,8 +90,14 @@ func (fs *Fs) WalkContent(walkFn func(path, key string, fi fs.FileInfo, err erro
 	return fs.walkFilesByExts(fs.Config.ContentDir, contentExts, walkFn)
 }
 
-func (fs *Fs) WalkLists(walkFn func(path, key string, fi fs.FileInfo, err error)) error {
-	return fs.walkFilesByExts(fs.Config.ListDir, listExts, walkFn)
+// WalkLists of recipients, skipping the suppression list
+func (f *Fs) WalkLists(walkFn func(path, key string, fi fs.FileInfo, err error)) error {
+	suppressed := filepath.Clean(f.SuppressionPath())
+	return f.walkFilesByExts(f.Config.ListDir, listExts, func(path, key string, fi fs.FileInfo, err error) {
+		if filepath.Clean(path) != suppressed {
+			walkFn(path, key, fi, err)
+		}
+	})
 }
 
 // Iteration helper to find all files with multiple possible extensions in a directory
@@ -104,3 +124,15 @@ func (f *Fs) IsFile(path string) bool {
 	s, err := f.Stat(path)
 	return err == nil && !s.IsDir()
 }
+
+// RealPath of name on the OS filesystem, if project is on one
+func (f *Fs) RealPath(name string) (string, bool) {
+	switch fs := f.Fs.(type) {
+	case *afero.BasePathFs:
+		p, err := fs.RealPath(name)
+		return p, err == nil
+	case *afero.OsFs:
+		return name, true
+	}
+	return "", false
+}
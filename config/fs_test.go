@@ -3,6 +3,7 @@ package config
 import (
 	"io/fs"
 	"path/filepath"
+	"slices"
 	"sort"
 	"testing"
 
@@ -29,6 +30,12 @@ func TestFsPathMethods(t *testing.T) {
 		t.Errorf("Expected ListPath 'subscribers/vips', got '%s'", path)
 	}
 
+	// Test ProjectDirs
+	expected := []string{"articles", "templates", "themes", "assets", "subscribers"}
+	if dirs := cfg.AppFs.ProjectDirs(); !slices.Equal(dirs, expected) {
+		t.Errorf("Expected ProjectDirs %v, got %v", expected, dirs)
+	}
+
 	// Test LayoutPath without theme - create the file first
 	afero.WriteFile(memFs, "templates/default.html", []byte("layout"), 0644)
 	if path := cfg.AppFs.LayoutPath("default.html"); path != "templates/default.html" {
@@ -238,7 +245,8 @@ func TestFsWalkLists(t *testing.T) {
 	afero.WriteFile(memFs, "lists/subscribers.yaml", []byte("subs"), 0644)
 	afero.WriteFile(memFs, "lists/vips.yml", []byte("vips"), 0644)
 	afero.WriteFile(memFs, "lists/groups/team.yaml", []byte("team"), 0644)
-	afero.WriteFile(memFs, "lists/config.toml", []byte("config"), 0644) // Should be ignored
+	afero.WriteFile(memFs, "lists/config.toml", []byte("config"), 0644)    // Should be ignored
+	afero.WriteFile(memFs, "lists/_suppressed.csv", []byte("email"), 0644) // Should be ignored
 
 	var found []string
 	var keys []string
@@ -309,3 +317,34 @@ func TestFsWalkNonexistentDirectory(t *testing.T) {
 		t.Errorf("Expected no files when directory doesn't exist, got %d: %v", len(found), found)
 	}
 }
+
+func TestFsSuppressionPath(t *testing.T) {
+	cfg, _ := LoadConfigFs(t.Context(), afero.NewMemMapFs())
+	if p := cfg.AppFs.SuppressionPath(); p != "lists/_suppressed.csv" {
+		t.Errorf("Expected default suppression path, got %q", p)
+	}
+
+	cfg.SuppressionList = "data/suppressed.csv"
+	if p := cfg.AppFs.SuppressionPath(); p != "data/suppressed.csv" {
+		t.Errorf("Expected configured suppression path, got %q", p)
+	}
+}
+
+func TestFsRealPath(t *testing.T) {
+	cases := []struct {
+		fs       afero.Fs
+		expected string
+		ok       bool
+	}{
+		{afero.NewBasePathFs(afero.NewOsFs(), "/project"), "/project/content", true},
+		{afero.NewOsFs(), "content", true},
+		{afero.NewMemMapFs(), "", false},
+	}
+
+	for _, c := range cases {
+		f := &Fs{Fs: c.fs}
+		if p, ok := f.RealPath("content"); p != c.expected || ok != c.ok {
+			t.Errorf("%T: expected %q (%v), got %q (%v)", c.fs, c.expected, c.ok, p, ok)
+		}
+	}
+}
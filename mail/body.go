@@ -0,0 +1,105 @@
+package mail
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	gmparser "github.com/yuin/goldmark/parser"
+)
+
+// Template actions in content (e.g. "{{ .Recipient.Name }}")
+var actionRegexp = regexp.MustCompile(`(?s)\{\{.*?\}\}`)
+
+// Placeholders of actions, which are left alone by Markdown
+var placeholderRegexp = regexp.MustCompile(`(?m)^[ \t]*(PBACTION\d+X)[ \t]*$`)
+
+// parseTextBody of Markdown content, for rendering as plain text
+func parseTextBody(lib *templateLibrary, name string, content *expandedContent) (Template, error) {
+	var out bytes.Buffer
+	out.Write(content.body)
+	for _, in := range content.inners {
+		fmt.Fprintf(&out, "{{ define %q }}%s{{ end }}", in.name, in.content)
+	}
+	return lib.parse(name, out.String())
+}
+
+// parseHTMLBody converts Markdown content into an html/template, so that
+// recipient data is escaped by its HTML context (e.g. attribute or URL),
+// rather than rendered as Markdown along with the content
+func parseHTMLBody(lib *templateLibrary, name string, content *expandedContent) (Template, error) {
+	body, err := markdownTemplate(content.body)
+	if err != nil {
+		return nil, err
+	}
+
+	// Inner content of shortcodes, without
+	// the paragraph of a single line
+	var out strings.Builder
+	out.WriteString(body)
+	for _, in := range content.inners {
+		inner, err := markdownTemplate(in.content)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&out, "{{ define %q }}%s{{ end }}", in.name, unwrapParagraph(inner))
+	}
+	return lib.parse(name, out.String())
+}
+
+// Convert Markdown with template actions to HTML with the same actions
+func markdownTemplate(content []byte) (string, error) {
+	// Hide actions from Markdown, which would
+	// escape them in URLs, or curl their quotes
+	var actions []string
+	masked := actionRegexp.ReplaceAllFunc(content, func(a []byte) []byte {
+		actions = append(actions, string(a))
+		return []byte(actionPlaceholder(len(actions) - 1))
+	})
+
+	// Actions on their own line (e.g. "{{ range }}") are blocks
+	// of their own, to not end up within paragraphs or lists
+	masked = placeholderRegexp.ReplaceAll(masked, []byte("\n$1\n"))
+
+	var buf bytes.Buffer
+	if err := markdown.Convert(masked, &buf); err != nil {
+		return "", err
+	}
+
+	// Sanitize HTML, in case "unsafe" Markdown is enabled
+	body := ugcPolicy.SanitizeBytes(buf.Bytes())
+
+	// Restore actions, unwrapping blocks first, and
+	// dropping them from heading IDs (lowercased)
+	pairs := make([]string, 0, 6*len(actions))
+	for i, a := range actions {
+		pairs = append(pairs, "<p>"+actionPlaceholder(i)+"</p>", a)
+	}
+	for i, a := range actions {
+		p := actionPlaceholder(i)
+		pairs = append(pairs, p, a, strings.ToLower(p), "")
+	}
+	return strings.NewReplacer(pairs...).Replace(string(body)), nil
+}
+
+func actionPlaceholder(i int) string {
+	return fmt.Sprintf("PBACTION%dX", i)
+}
+
+// Goldmark configured to match GoHugo and GFM,
+// which is safe for concurrent use
+var markdown = goldmark.New(
+	goldmark.WithExtensions(
+		extension.GFM,
+		extension.Footnote,
+		extension.Typographer,
+		extension.DefinitionList,
+	),
+	goldmark.WithParserOptions(
+		gmparser.WithAttribute(),
+		gmparser.WithAutoHeadingID(),
+	),
+)
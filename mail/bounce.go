@@ -0,0 +1,319 @@
+package mail
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	netmail "net/mail"
+	"net/textproto"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rykov/paperboy/config"
+	"github.com/rykov/paperboy/mail/send"
+	"github.com/spf13/afero"
+)
+
+// Kinds of bounces
+const (
+	BounceHard      = "hard"      // Permanent failure (5.x.x)
+	BounceSoft      = "soft"      // Temporary failure (4.x.x)
+	BounceComplaint = "complaint" // ARF feedback report (e.g. spam)
+)
+
+// Bounce is a failed delivery or complaint of a single recipient
+type Bounce struct {
+	Email      string
+	Campaign   string // From the delivery ledger, if found
+	Kind       string
+	Status     string // Enhanced status code (e.g. "5.1.1")
+	Diagnostic string
+	MessageID  string // Of the original message
+	Time       time.Time
+}
+
+// BounceReport summarizes imported bounce messages
+type BounceReport struct {
+	Bounces      []Bounce
+	Unrecognized int // Not a DSN or ARF report (e.g. auto-replies)
+	Unmatched    int // Reports without a recipient
+	Suppressed   int // Newly suppressed addresses
+}
+
+// Count bounces of a kind
+func (r *BounceReport) Count(kind string) int {
+	n := 0
+	for _, b := range r.Bounces {
+		if b.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// ImportBounces parses RFC 3464 delivery status notifications and ARF
+// feedback reports of a mailbox (see ReadMailbox), and suppresses hard
+// bounces and complaints, unless cfg.DryRun. Recipients are identified
+// by the original Message-ID in the delivery ledger, then by VERP of the
+// envelope sender (see "envelopeFrom" config), then as reported
+func ImportBounces(cfg *config.AConfig, fs afero.Fs, path string) (*BounceReport, error) {
+	ledger, err := ledgerRecipients(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &BounceReport{}
+	err = ReadMailbox(fs, path, func(r io.Reader) error {
+		bounces, err := ParseBounces(r)
+		if err != nil || len(bounces) == 0 {
+			report.Unrecognized++
+			return nil
+		}
+
+		for _, b := range bounces {
+			if sent, ok := ledger[b.MessageID]; ok {
+				b.Email, b.Campaign = sent.Email, sent.Campaign
+			}
+			if b.Email == "" {
+				report.Unmatched++
+				continue
+			}
+			report.Bounces = append(report.Bounces, b)
+		}
+		return nil
+	})
+	if err != nil || cfg.DryRun {
+		return report, err
+	}
+
+	err = UpdateSuppressions(cfg, func(s *Suppressions) error {
+		for _, b := range report.Bounces {
+			e := Suppression{Email: b.Email, Time: b.Time, Campaign: b.Campaign}
+			switch b.Kind {
+			case BounceHard:
+				e.Reason = SuppressBounced
+			case BounceComplaint:
+				e.Reason = SuppressComplained
+			default:
+				continue // Soft bounces may still be delivered
+			}
+			if s.Insert(e) {
+				report.Suppressed++
+			}
+		}
+		return nil
+	})
+	return report, err
+}
+
+// Sent messages in the ledger by Message-ID
+func ledgerRecipients(cfg *config.AConfig) (map[string]*send.Result, error) {
+	out := map[string]*send.Result{}
+	path := cfg.Delivery.Ledger
+	if path == "" {
+		return out, nil
+	}
+
+	err := send.ReadLedger(deliveryFs(cfg), path, func(r *send.Result) {
+		if id := messageID(r.MessageID); id != "" {
+			out[id] = r
+		}
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return out, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read ledger %s: %w", path, err)
+	}
+	return out, nil
+}
+
+// ParseBounces of a DSN (RFC 3464) or ARF (RFC 5965) message, which
+// are empty for other messages. When the bounce was sent to a VERP
+// address, it takes precedence over the reported recipients.
+func ParseBounces(r io.Reader) ([]Bounce, error) {
+	msg, err := netmail.ReadMessage(r)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &bounceParser{}
+	if err := p.walk(textproto.MIMEHeader(msg.Header), msg.Body); err != nil {
+		return nil, err
+	}
+
+	// Bounces are delivered to the envelope sender (Return-Path)
+	verpAddr := ""
+	for _, field := range []string{
+		msg.Header.Get("X-Original-To"), msg.Header.Get("Delivered-To"),
+		msg.Header.Get("To"), p.originalReturnPath, p.originalMailFrom,
+	} {
+		if email, ok := ParseVERP(headerAddress(field)); ok {
+			verpAddr = email
+			break
+		}
+	}
+
+	t, _ := msg.Header.Date()
+	for i := range p.bounces {
+		b := &p.bounces[i]
+		b.MessageID, b.Time = p.originalMessageID, t
+		if verpAddr != "" {
+			b.Email = verpAddr
+		} else if b.Email == "" {
+			b.Email = headerAddress(p.originalTo)
+		}
+	}
+	return p.bounces, nil
+}
+
+type bounceParser struct {
+	bounces []Bounce
+
+	// From the original message or its headers
+	originalMessageID  string
+	originalReturnPath string
+	originalTo         string
+	originalMailFrom   string // ARF Original-Mail-From
+}
+
+// Walk MIME parts of the report for its machine-readable parts
+func (p *bounceParser) walk(header textproto.MIMEHeader, body io.Reader) error {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return nil // Ignore unknown parts
+	}
+
+	switch strings.ToLower(header.Get("Content-Transfer-Encoding")) {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		if !strings.HasPrefix(mediaType, "multipart/") {
+			body = quotedprintable.NewReader(body)
+		}
+	}
+
+	switch mediaType {
+	case "multipart/report", "multipart/mixed", "multipart/related", "multipart/alternative":
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextRawPart()
+			if err == io.EOF {
+				return nil
+			} else if err != nil {
+				return err
+			}
+			if err := p.walk(part.Header, part); err != nil {
+				return err
+			}
+		}
+	case "message/delivery-status", "message/global-delivery-status":
+		return p.readDeliveryStatus(body)
+	case "message/feedback-report":
+		return p.readFeedbackReport(body)
+	case "message/rfc822", "message/global", "text/rfc822-headers", "message/global-headers":
+		h, _ := textproto.NewReader(bufio.NewReader(body)).ReadMIMEHeader()
+		p.originalMessageID = messageID(h.Get("Message-Id"))
+		p.originalReturnPath = h.Get("Return-Path")
+		p.originalTo = h.Get("To")
+	}
+	return nil
+}
+
+// Per-recipient fields follow the per-message fields (RFC 3464)
+func (p *bounceParser) readDeliveryStatus(body io.Reader) error {
+	tr := textproto.NewReader(bufio.NewReader(body))
+	if _, err := tr.ReadMIMEHeader(); err != nil {
+		return nil // No recipient fields
+	}
+
+	for {
+		h, err := tr.ReadMIMEHeader()
+		if email := typedAddress(h.Get("Final-Recipient")); email != "" {
+			b := Bounce{
+				Email:      email,
+				Status:     statusCode(h.Get("Status")),
+				Diagnostic: typedValue(h.Get("Diagnostic-Code")),
+			}
+			if b.Kind = bounceKind(h.Get("Action"), b.Status); b.Kind != "" {
+				p.bounces = append(p.bounces, b)
+			}
+		}
+		if err != nil {
+			return nil
+		}
+	}
+}
+
+// A single block of fields of an ARF report (RFC 5965)
+func (p *bounceParser) readFeedbackReport(body io.Reader) error {
+	h, _ := textproto.NewReader(bufio.NewReader(body)).ReadMIMEHeader()
+	p.originalMailFrom = h.Get("Original-Mail-From")
+	if strings.EqualFold(h.Get("Feedback-Type"), "not-spam") {
+		return nil
+	}
+
+	p.bounces = append(p.bounces, Bounce{
+		Email:      strings.Trim(strings.TrimSpace(h.Get("Original-Rcpt-To")), "<>"),
+		Kind:       BounceComplaint,
+		Diagnostic: strings.TrimSpace(h.Get("Feedback-Type")),
+	})
+	return nil
+}
+
+// Hard or soft bounce by status code, or action without one,
+// and blank for successful deliveries
+func bounceKind(action, status string) string {
+	action = strings.ToLower(strings.TrimSpace(action))
+	switch {
+	case action == "delivered" || action == "relayed" || action == "expanded":
+		return ""
+	case strings.HasPrefix(status, "5."):
+		return BounceHard
+	case strings.HasPrefix(status, "4."):
+		return BounceSoft
+	case action == "failed":
+		return BounceHard
+	case action == "delayed":
+		return BounceSoft
+	}
+	return ""
+}
+
+// Enhanced status code without comments (e.g. "5.1.1 (bad mailbox)")
+func statusCode(field string) string {
+	if f := strings.Fields(field); len(f) > 0 {
+		return f[0]
+	}
+	return ""
+}
+
+// Value of a "type; value" field (e.g. "rfc822; jane@example.com")
+func typedValue(field string) string {
+	if _, v, ok := strings.Cut(field, ";"); ok {
+		field = v
+	}
+	return strings.Join(strings.Fields(field), " ")
+}
+
+func typedAddress(field string) string {
+	return strings.Trim(typedValue(field), "<>")
+}
+
+// Address of a header with a single one
+func headerAddress(field string) string {
+	if a, err := netmail.ParseAddress(field); err == nil {
+		return a.Address
+	}
+	return ""
+}
+
+// Message-ID without angle brackets
+func messageID(id string) string {
+	return strings.Trim(strings.TrimSpace(id), "<>")
+}
@@ -0,0 +1,265 @@
+package mail
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/rykov/paperboy/config"
+	"github.com/spf13/afero"
+)
+
+const testDSN = `From: MAILER-DAEMON@mx.example.org
+To: bounces+jane=example.com@example.org
+Subject: Undelivered Mail Returned to Sender
+Date: Thu, 02 Jan 2025 03:04:05 +0000
+MIME-Version: 1.0
+Content-Type: multipart/report; report-type=delivery-status; boundary="BOUNDARY"
+
+--BOUNDARY
+Content-Type: text/plain
+
+Your message could not be delivered.
+
+--BOUNDARY
+Content-Type: message/delivery-status
+
+Reporting-MTA: dns; mx.example.org
+
+Final-Recipient: rfc822; jane@example.com
+Action: failed
+Status: 5.1.1
+Diagnostic-Code: smtp; 550 5.1.1 <jane@example.com>:
+ Recipient address rejected
+
+--BOUNDARY
+Content-Type: text/rfc822-headers
+
+From: news@example.org
+To: jane@example.com
+Message-ID: <1@example.org>
+Subject: Hello
+
+--BOUNDARY--
+`
+
+const testDelayDSN = `From: MAILER-DAEMON@mx.example.org
+To: news@example.org
+Content-Type: multipart/report; report-type=delivery-status; boundary="B"
+
+--B
+Content-Type: message/delivery-status
+
+Reporting-MTA: dns; mx.example.org
+
+Final-Recipient: rfc822;bob@example.com
+Action: delayed
+Status: 4.2.2 (mailbox full)
+
+Final-Recipient: rfc822;carol@example.com
+Action: delivered
+Status: 2.0.0
+
+--B
+Content-Type: message/rfc822
+
+From: news@example.org
+To: bob@example.com
+Message-ID: <2@example.org>
+
+Hello
+--B--
+`
+
+const testARF = `From: abuse@isp.example
+To: abuse@example.org
+Content-Type: multipart/report; report-type=feedback-report; boundary="ARF"
+
+--ARF
+Content-Type: text/plain
+
+This is an abuse report.
+
+--ARF
+Content-Type: message/feedback-report
+
+Feedback-Type: abuse
+User-Agent: ISP/1.0
+Version: 1
+Original-Mail-From: <bounces+dan=example.com@example.org>
+
+--ARF
+Content-Type: text/rfc822-headers
+
+From: news@example.org
+To: dan@example.com
+Message-ID: <3@example.org>
+
+--ARF--
+`
+
+const testAutoReply = `From: eve@example.com
+To: news@example.org
+Subject: Out of office
+
+I am away.
+`
+
+func TestParseBounces(t *testing.T) {
+	testCases := []struct {
+		name     string
+		message  string
+		expected []Bounce
+	}{
+		{"dsn", testDSN, []Bounce{{
+			Email:      "jane@example.com",
+			Kind:       BounceHard,
+			Status:     "5.1.1",
+			Diagnostic: "550 5.1.1 <jane@example.com>: Recipient address rejected",
+			MessageID:  "1@example.org",
+			Time:       time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		}}},
+		{"delayed", testDelayDSN, []Bounce{{
+			Email:     "bob@example.com",
+			Kind:      BounceSoft,
+			Status:    "4.2.2",
+			MessageID: "2@example.org",
+		}}},
+		{"arf", testARF, []Bounce{{
+			Email:      "dan@example.com",
+			Kind:       BounceComplaint,
+			Diagnostic: "abuse",
+			MessageID:  "3@example.org",
+		}}},
+		{"auto-reply", testAutoReply, nil},
+	}
+
+	for _, tc := range testCases {
+		bounces, err := ParseBounces(strings.NewReader(tc.message))
+		if err != nil {
+			t.Fatalf("%s: ParseBounces() failed: %s", tc.name, err)
+		}
+		if d := cmp.Diff(tc.expected, bounces, cmp.Comparer(time.Time.Equal)); d != "" {
+			t.Errorf("%s: unexpected bounces: %s", tc.name, d)
+		}
+	}
+}
+
+func TestBounceKind(t *testing.T) {
+	testCases := []struct{ action, status, expected string }{
+		{"failed", "5.1.1", BounceHard},
+		{"failed", "", BounceHard},
+		{"failed", "4.4.7", BounceSoft},
+		{"delayed", "", BounceSoft},
+		{"Delivered", "2.0.0", ""},
+		{"relayed", "", ""},
+		{"", "5.7.1", BounceHard},
+		{"", "", ""},
+	}
+
+	for _, tc := range testCases {
+		if k := bounceKind(tc.action, tc.status); k != tc.expected {
+			t.Errorf("bounceKind(%q, %q) = %q, expected %q", tc.action, tc.status, k, tc.expected)
+		}
+	}
+}
+
+func TestImportBounces(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	cfg, _ := config.LoadConfigFs(t.Context(), fs)
+	cfg.Delivery.Ledger = "logs/ledger.jsonl"
+
+	// Bob's delayed bounce is matched in the ledger
+	afero.WriteFile(fs, "logs/ledger.jsonl", []byte(
+		`{"campaign":"news","email":"robert@example.com","messageId":"<2@example.org>","state":"sent"}`+"\n",
+	), 0644)
+
+	// Maildir with one message in "new" and two in "cur"
+	afero.WriteFile(fs, "bounces/new/1", []byte(testDSN), 0644)
+	afero.WriteFile(fs, "bounces/cur/2:2,S", []byte(testDelayDSN), 0644)
+	afero.WriteFile(fs, "bounces/cur/3:2,S", []byte(testARF), 0644)
+	afero.WriteFile(fs, "bounces/cur/4:2,S", []byte(testAutoReply), 0644)
+	afero.WriteFile(fs, "bounces/tmp/5", []byte(testDSN), 0644)
+
+	report, err := ImportBounces(cfg, fs, "bounces")
+	if err != nil {
+		t.Fatalf("ImportBounces() failed: %s", err)
+	}
+
+	if n := len(report.Bounces); n != 3 {
+		t.Fatalf("Expected 3 bounces, got %d: %+v", n, report.Bounces)
+	}
+	counts := []int{report.Count(BounceHard), report.Count(BounceSoft), report.Count(BounceComplaint), report.Unrecognized, report.Suppressed}
+	if d := cmp.Diff([]int{1, 1, 1, 1, 2}, counts); d != "" {
+		t.Errorf("Unexpected counts (hard, soft, complaint, unrecognized, suppressed): %s", d)
+	}
+	if b := report.Bounces[1]; b.Email != "robert@example.com" || b.Campaign != "news" {
+		t.Errorf("Expected bounce matched in ledger: %+v", b)
+	}
+
+	s, err := LoadSuppressions(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Suppression{
+		{Email: "jane@example.com", Reason: SuppressBounced, Time: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{Email: "dan@example.com", Reason: SuppressComplained},
+	}
+	if d := cmp.Diff(expected, s.All()); d != "" {
+		t.Errorf("Unexpected suppressions: %s", d)
+	}
+
+	// Dry run does not suppress
+	cfg.DryRun = true
+	afero.WriteFile(fs, "more.eml", []byte(strings.Replace(testDSN, "jane", "joe", -1)), 0644)
+	if report, err := ImportBounces(cfg, fs, "more.eml"); err != nil {
+		t.Fatal(err)
+	} else if report.Count(BounceHard) != 1 || report.Suppressed != 0 {
+		t.Errorf("Unexpected dry-run report: %+v", report)
+	}
+	if s, _ := LoadSuppressions(cfg); s.Len() != 2 {
+		t.Errorf("Dry run should not suppress: %+v", s.All())
+	}
+}
+
+func TestReadMailbox(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	afero.WriteFile(fs, "inbox.mbox", []byte(
+		"From MAILER-DAEMON Thu Jan  2 03:04:05 2025\nSubject: One\n\n>From the start\nbody\n\n"+
+			"From MAILER-DAEMON Thu Jan  2 03:04:06 2025\nSubject: Two\n\nbody\n",
+	), 0644)
+	afero.WriteFile(fs, "dir/a.eml", []byte("Subject: A\n\nbody\n"), 0644)
+	afero.WriteFile(fs, "dir/.hidden", []byte("Subject: Hidden\n\n"), 0644)
+
+	testCases := []struct {
+		path     string
+		expected []string
+	}{
+		{"inbox.mbox", []string{
+			"Subject: One\n\nFrom the start\nbody\n\n",
+			"Subject: Two\n\nbody\n",
+		}},
+		{"dir", []string{"Subject: A\n\nbody\n"}},
+	}
+
+	for _, tc := range testCases {
+		var messages []string
+		err := ReadMailbox(fs, tc.path, func(r io.Reader) error {
+			b, err := io.ReadAll(r)
+			messages = append(messages, string(b))
+			return err
+		})
+		if err != nil {
+			t.Fatalf("%s: ReadMailbox() failed: %s", tc.path, err)
+		}
+		if d := cmp.Diff(tc.expected, messages); d != "" {
+			t.Errorf("%s: unexpected messages: %s", tc.path, d)
+		}
+	}
+
+	if err := ReadMailbox(fs, "missing", func(io.Reader) error { return nil }); err == nil {
+		t.Errorf("Expected error for missing mailbox")
+	}
+}
@@ -7,22 +7,16 @@ import (
 	html "html/template"
 	"io"
 	"path/filepath"
+	"strings"
 	"text/template"
 
 	"github.com/ghodss/yaml"
 	"github.com/jtacoma/uritemplates"
-	"github.com/microcosm-cc/bluemonday"
 	"github.com/rykov/paperboy/config"
 	"github.com/rykov/paperboy/parser"
 	"github.com/spf13/afero"
 	"github.com/spf13/cast"
 	"github.com/wneessen/go-mail"
-
-	"github.com/charmbracelet/glamour"
-	"github.com/charmbracelet/glamour/styles"
-	"github.com/yuin/goldmark"
-	"github.com/yuin/goldmark/extension"
-	gmparser "github.com/yuin/goldmark/parser"
 )
 
 // Shared empty parameters
@@ -36,6 +30,9 @@ type tmplContext struct {
 	Content html.HTML
 	Subject string
 	renderContext
+
+	// For "List-Unsubscribe" header
+	unsubscribeMailto string
 }
 
 type Campaign struct {
@@ -46,9 +43,30 @@ type Campaign struct {
 	// For logging, etc
 	ID string
 
+	// Recipient list (for delivery state)
+	ListID string
+
+	// Positions of Recipients in the list, when
+	// some were removed (see Campaign.Suppress)
+	positions []int
+
+	// Custom headers (from config and frontmatter)
+	headers []headerTemplate
+
+	// Layouts, partials and shortcodes
+	textTemplates *templateLibrary
+	htmlTemplates *templateLibrary
+
+	// Compiled layouts, etc (shared by recipients)
+	renderer *renderer
+
 	// Internal templates
-	bodyTemplate           *template.Template
-	unsubscribeURLTemplate *uritemplates.UriTemplate
+	subjectTemplate           *template.Template
+	envelopeFromTemplate      *template.Template
+	bodyTemplate              Template
+	htmlBodyTemplate          Template
+	unsubscribeURLTemplate    *uritemplates.UriTemplate
+	unsubscribeMailtoTemplate *uritemplates.UriTemplate
 
 	// Configuration for everything else
 	MsgOpts []mail.MsgOption
@@ -62,7 +80,6 @@ func (c *Campaign) MessageFor(i int) (*mail.Msg, error) {
 
 func (c *Campaign) renderMessage(m *mail.Msg, i int) error {
 	var content bytes.Buffer
-	appFs := c.Config.AppFs
 
 	// Get template context
 	ctx, err := c.templateContextFor(i)
@@ -71,27 +88,30 @@ func (c *Campaign) renderMessage(m *mail.Msg, i int) error {
 	}
 
 	// Render subject first so it's available to templates
-	subject := cast.ToString(ctx.Campaign.subject)
-	ctx.Subject, err = renderSubject(subject, ctx)
+	ctx.Subject, err = executeTemplate(nil, c.subjectTemplate, ctx)
 	if err != nil {
 		return err
 	}
 
-	// Render template body with text/template
+	// Render template body with text/template for the plain part
 	if err := c.bodyTemplate.Execute(&content, ctx); err != nil {
 		return err
 	}
 
+	// ... and with html/template for the HTML part
+	var htmlContent bytes.Buffer
+	if err := c.htmlBodyTemplate.Execute(&htmlContent, ctx); err != nil {
+		return err
+	}
+
 	// Render plain content into a layout (no Markdown)
-	tLayoutFile := appFs.LayoutPath("_default.text")
-	plainBody, err := c.renderPlain(content.Bytes(), tLayoutFile, ctx)
+	plainBody, err := c.renderPlain(content.Bytes(), ctx)
 	if err != nil {
 		return err
 	}
 
-	// Render content through Markdown and into a layout
-	hLayoutFile := appFs.LayoutPath("_default.html")
-	htmlBody, err := c.renderHTML(content.Bytes(), hLayoutFile, ctx)
+	// Render HTML content into a layout
+	htmlBody, err := c.renderHTML(htmlContent.Bytes(), ctx)
 	if err != nil {
 		return err
 	}
@@ -100,13 +120,21 @@ func (c *Campaign) renderMessage(m *mail.Msg, i int) error {
 	m.Reset() // Return to NewMsg state
 	errT := addMessageRecipient(m, ctx)
 	errF := m.From(cast.ToString(ctx.Campaign.From))
+	errE := c.setEnvelopeFrom(m, ctx)
 	m.Subject(cast.ToString(ctx.Subject))
+	m.SetMessageID() // For delivery ledger
 	m.SetDate()
 
 	// Populate mailer name & version
 	xm := fmt.Sprintf(xMailer, c.Config.Build.Version)
 	m.SetGenHeader("X-Mailer", xm)
 
+	// Unsubscribe links for mail clients
+	setListUnsubscribe(m, ctx)
+
+	// Custom headers (e.g. Reply-To or Precedence)
+	errH := c.setHeaders(m, ctx)
+
 	// Populate plain & HTML body
 	m.SetBodyString(mail.TypeTextPlain, plainBody)
 	m.AddAlternativeString(mail.TypeTextHTML, htmlBody)
@@ -120,10 +148,49 @@ func (c *Campaign) renderMessage(m *mail.Msg, i int) error {
 		}
 	}
 
-	errs = append(errs, errT, errF)
+	errs = append(errs, errT, errF, errE, errH)
 	return errors.Join(errs...)
 }
 
+// Populates "List-Unsubscribe" (RFC 2369) with rendered unsubscribe
+// URL and mailto, and one-click unsubscribe (RFC 8058) for the URL
+func setListUnsubscribe(m *mail.Msg, ctx *tmplContext) {
+	var links []string
+	if mu := ctx.unsubscribeMailto; mu != "" {
+		links = append(links, "<"+mu+">")
+	}
+
+	u := ctx.UnsubscribeURL
+	web := strings.HasPrefix(u, "https://") || strings.HasPrefix(u, "http://")
+	if web {
+		links = append(links, "<"+u+">")
+	}
+
+	if len(links) == 0 {
+		return
+	}
+
+	m.SetGenHeader(mail.HeaderListUnsubscribe, links...)
+	if web {
+		m.SetGenHeader(mail.HeaderListUnsubscribePost, "List-Unsubscribe=One-Click")
+	}
+}
+
+// Renders SMTP sender, when configured, which remains "From" otherwise
+func (c *Campaign) setEnvelopeFrom(m *mail.Msg, ctx *tmplContext) error {
+	if c.envelopeFromTemplate == nil {
+		return nil
+	}
+
+	from, err := executeTemplate(nil, c.envelopeFromTemplate, ctx)
+	if err != nil {
+		return fmt.Errorf("failed to render envelopeFrom: %w", err)
+	} else if from = strings.TrimSpace(from); from == "" {
+		return nil
+	}
+	return m.EnvelopeFrom(from)
+}
+
 // Populates default recipient or renders "To" campaign template
 func addMessageRecipient(m *mail.Msg, ctx *tmplContext) error {
 	toTmpl := ctx.Campaign.to
@@ -132,7 +199,7 @@ func addMessageRecipient(m *mail.Msg, ctx *tmplContext) error {
 		return m.AddToFormat(r.Name(), r.Email())
 	}
 
-	tmpl, err := template.New("to").Parse(toTmpl)
+	tmpl, err := template.New("to").Funcs(templateFuncs).Parse(toTmpl)
 	if err != nil {
 		return err
 	}
@@ -153,17 +220,36 @@ func (c *Campaign) templateContextFor(i int) (*tmplContext, error) {
 		Address:   c.Config.Address,
 	}
 
+	// Variables of URI templates, with a signed token for
+	// the server's unsubscribe page (see server.UnsubscribePath)
+	var vars map[string]interface{}
+	if c.unsubscribeURLTemplate != nil || c.unsubscribeMailtoTemplate != nil {
+		vars = ctx.toFlatMap()
+		if secret := c.Config.UnsubscribeSecret; secret != "" {
+			vars["token"] = UnsubscribeToken(secret, c.ID, ctx.Recipient.Email())
+		}
+	}
+
 	// Populate UnsubscribeURL using uritemplates
 	if t := c.unsubscribeURLTemplate; t != nil {
-		uu, err := t.Expand(ctx.toFlatMap())
+		uu, err := t.Expand(vars)
 		if err != nil {
 			return nil, err
 		}
 		ctx.UnsubscribeURL = uu
 	}
 
+	out := &tmplContext{renderContext: ctx}
+	if t := c.unsubscribeMailtoTemplate; t != nil {
+		mu, err := t.Expand(vars)
+		if err != nil {
+			return nil, err
+		}
+		out.unsubscribeMailto = mu
+	}
+
 	// Render template body with text/template
-	return &tmplContext{renderContext: ctx}, nil
+	return out, nil
 }
 
 // Populate campaign and a receipient list into a Campaign object
@@ -183,8 +269,13 @@ func LoadCampaign(cfg *config.AConfig, tmplID, listID string) (*Campaign, error)
 		return nil, fmt.Errorf("failed to load campain's recipients: %w", err)
 	}
 
-	// Populate recipients, and fire!
+	// Populate recipients, without suppressed addresses
 	campaign.Recipients = who
+	campaign.ListID = listID
+	if err := campaign.ExcludeSuppressed(cfg); err != nil {
+		return nil, err
+	}
+
 	return campaign, nil
 }
 
@@ -211,8 +302,39 @@ func LoadContent(cfg *config.AConfig, tmplID string) (*Campaign, error) {
 		fMeta = newCampaign(cfg, emptyParams)
 	}
 
+	// Select layouts by frontmatter and content section
+	layouts, err := findLayouts(cfg.AppFs, tmplID, &fMeta)
+	if err != nil {
+		return nil, err
+	}
+
+	// Expand shortcodes, which must exist in layouts
+	textTemplates := newTemplateLibrary(cfg.AppFs, false)
+	htmlTemplates := newTemplateLibrary(cfg.AppFs, true)
+	content, err := expandShortcodes(email.Content())
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range content.names {
+		if htmlTemplates.find("shortcodes", name) == "" {
+			return nil, fmt.Errorf("shortcode %q not found", name)
+		}
+	}
+
 	// Parse email template for processing
-	tmpl, err := template.New(tmplID).Parse(string(email.Content()))
+	tmpl, err := parseTextBody(textTemplates, tmplID, content)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse email template converted to HTML
+	htmlTmpl, err := parseHTMLBody(htmlTemplates, tmplID, content)
+	if err != nil {
+		return nil, err
+	}
+
+	// Compile layouts once for all recipients
+	renderer, err := compileRenderer(cfg.AppFs, layouts, textTemplates, htmlTemplates)
 	if err != nil {
 		return nil, err
 	}
@@ -224,7 +346,7 @@ func LoadContent(cfg *config.AConfig, tmplID string) (*Campaign, error) {
 	}
 
 	// Prepare URI template for UnsubscribeURL
-	var unsubscribe *uritemplates.UriTemplate
+	var unsubscribe, unsubscribeMailto *uritemplates.UriTemplate
 	if uu := cfg.UnsubscribeURL; uu != "" {
 		unsubscribe, err = uritemplates.Parse(uu)
 		if err != nil {
@@ -232,8 +354,40 @@ func LoadContent(cfg *config.AConfig, tmplID string) (*Campaign, error) {
 		}
 	}
 
+	// Prepare URI template for "mailto:" of List-Unsubscribe
+	if mu := cfg.UnsubscribeMailto; mu != "" {
+		if !strings.HasPrefix(strings.ToLower(mu), "mailto:") {
+			mu = "mailto:" + mu
+		}
+		unsubscribeMailto, err = uritemplates.Parse(mu)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Prepare subject template
+	subject, err := template.New("subject").Funcs(templateFuncs).Parse(fMeta.subject)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse subject: %w", err)
+	}
+
+	// Prepare SMTP sender template (e.g. for VERP)
+	var envelopeFrom *template.Template
+	if ef := fMeta.envelopeFrom; ef != "" {
+		envelopeFrom, err = template.New("envelopeFrom").Funcs(templateFuncs).Parse(ef)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse envelopeFrom: %w", err)
+		}
+	}
+
+	// Prepare custom headers, with frontmatter taking precedence
+	headers, err := parseHeaders(cfg.Headers, fMeta.headers)
+	if err != nil {
+		return nil, err
+	}
+
 	// Prepare []mail.MsgOption
-	opts, err := msgOptions(cfg)
+	opts, err := msgOptions(cfg, signedHeaders(headers))
 	if err != nil {
 		return nil, err
 	}
@@ -247,8 +401,16 @@ func LoadContent(cfg *config.AConfig, tmplID string) (*Campaign, error) {
 		Config:  cfg,
 		MsgOpts: opts,
 
-		unsubscribeURLTemplate: unsubscribe,
-		bodyTemplate:           tmpl,
+		unsubscribeURLTemplate:    unsubscribe,
+		unsubscribeMailtoTemplate: unsubscribeMailto,
+		subjectTemplate:           subject,
+		bodyTemplate:              tmpl,
+		htmlBodyTemplate:          htmlTmpl,
+		renderer:                  renderer,
+		textTemplates:             textTemplates,
+		htmlTemplates:             htmlTemplates,
+		headers:                   headers,
+		envelopeFromTemplate:      envelopeFrom,
 	}, nil
 }
 
@@ -297,92 +459,25 @@ func parseTemplate(appFs *config.Fs, path string) (parser.Email, error) {
 	return parser.ReadFrom(file)
 }
 
-func (c *Campaign) renderPlain(body []byte, layoutPath string, ctx *tmplContext) (string, error) {
-	layout, err := loadTemplate(c.Config.AppFs, layoutPath, "{{ .Content }}")
+// Render content through Markdown to plain text, and into a layout
+func (c *Campaign) renderPlain(body []byte, ctx *tmplContext) (string, error) {
+	r := c.renderer
+	plain, err := r.plain.do(string(body), r.markdownToText)
 	if err != nil {
 		return "", err
 	}
-
-	// Parse template first to bail on errors, if broken
-	tmpl, err := template.New(filepath.Base(layoutPath)).Parse(layout)
-	if err != nil {
-		return "", err
-	}
-
-	// For markdown, we use notty style with tweaks
-	style := *styles.DefaultStyles["notty"]
-	style.Link.BlockPrefix = "("
-	style.Link.BlockSuffix = ")"
-
-	// Render markdown to plain text
-	r, err := glamour.NewTermRenderer(glamour.WithStyles(style), glamour.WithWordWrap(-1))
-	if err != nil {
-		return "", err
-	}
-
-	body, err = r.RenderBytes(body)
-	if err != nil {
-		return "", err
-	}
-
-	// Strip all HTML from campaign
-	body = bluemonday.StrictPolicy().SanitizeBytes(body)
-
-	// Apply text/template
-	return executeTemplate(body, tmpl, ctx)
+	return executeTemplate([]byte(plain), r.textLayout, ctx)
 }
 
-// TODO: Uses a common text/template renderer, should use html/template instead
-func (c *Campaign) renderHTML(body []byte, layoutPath string, ctx *tmplContext) (string, error) {
-	layout, err := loadTemplate(c.Config.AppFs, layoutPath, "<html><body>{{ .Content }}</body></html>")
-	if err != nil {
-		return "", err
-	}
-
-	tmpl, err := html.New(filepath.Base(layoutPath)).Parse(layout)
-	if err != nil {
-		return "", err
-	}
-
-	// Configure Goldmark to match GoHugo and GFM
-	md := goldmark.New(
-		goldmark.WithExtensions(
-			extension.GFM,
-			extension.Footnote,
-			extension.Typographer,
-			extension.DefinitionList,
-		),
-		goldmark.WithParserOptions(
-			gmparser.WithAttribute(),
-			gmparser.WithAutoHeadingID(),
-		),
-	)
-
-	// Render Markdown
-	var buf bytes.Buffer
-	if err := md.Convert(body, &buf); err != nil {
-		return "", err
-	}
-
-	// Sanitize HTML, in case "unsafe" Markdown is enabled
-	bodyHTML := bluemonday.UGCPolicy().SanitizeBytes(buf.Bytes())
-
-	// Render inner template
-	tmplOut, err := executeTemplate(bodyHTML, tmpl, ctx)
+// Render HTML body (see parseHTMLBody) into a layout
+func (c *Campaign) renderHTML(body []byte, ctx *tmplContext) (string, error) {
+	tmplOut, err := executeTemplate(body, c.renderer.htmlLayout, ctx)
 	if err != nil {
 		return "", err
 	}
 
 	// Inline CSS into elements "style" attribute
-	return c.inlineStylesheets(layoutPath, tmplOut)
-}
-
-func renderSubject(subject string, ctx *tmplContext) (string, error) {
-	tmpl, err := template.New("subject").Parse(subject)
-	if err != nil {
-		return "", err
-	}
-	return executeTemplate(nil, tmpl, ctx)
+	return c.inlineStylesheets(c.renderer.layouts.html, tmplOut)
 }
 
 func loadTemplate(appFs *config.Fs, path string, defaultTemplate string) (string, error) {
@@ -410,11 +505,11 @@ func executeTemplate(body []byte, tmpl Template, ctx *tmplContext) (string, erro
 	return out.String(), err
 }
 
-func msgOptions(cfg *config.AConfig) ([]mail.MsgOption, error) {
+func msgOptions(cfg *config.AConfig, headers []string) ([]mail.MsgOption, error) {
 	var opts = []mail.MsgOption{}
 
 	if len(cfg.DKIM) > 0 {
-		mw, err := DKIMMiddleware(cfg)
+		mw, err := DKIMMiddleware(cfg, headers...)
 		if err != nil {
 			return nil, err
 		}
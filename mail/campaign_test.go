@@ -788,3 +788,59 @@ Hello World!`
 		t.Errorf("Error should mention frontmatter parsing issue, got: %v", err)
 	}
 }
+
+func TestCampaignHTMLEscaping(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	afero.WriteFile(memFs, "content/c1.md", []byte(`---
+subject: Hello
+---
+# Hi {{ .Recipient.Name }}
+
+[Your profile](https://example.com/p?id={{ .Recipient.id }}) and [site]({{ .Recipient.site }})
+
+{{ range .Campaign.Params.items }}
+- "{{ . }}"
+{{ end }}
+`), 0644)
+
+	cfg, _ := config.LoadConfigFs(t.Context(), memFs)
+	cfg.From = "news@example.com"
+	c, err := LoadContent(cfg, "c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.EmailMeta.Params["items"] = []string{"*one*", "two"}
+	c.Recipients, _ = MapsToRecipients([]map[string]any{{
+		"email": "jane@example.com",
+		"name":  "<script>alert(1)</script>",
+		"id":    "a&b c",
+		"site":  "javascript:alert(1)",
+	}})
+
+	ctx, _ := c.templateContextFor(0)
+	var out bytes.Buffer
+	if err := c.htmlBodyTemplate.Execute(&out, ctx); err != nil {
+		t.Fatalf("Execute() failed: %s", err)
+	}
+
+	html := out.String()
+	for _, e := range []string{
+		`<h1 id="hi-">Hi &lt;script&gt;alert(1)&lt;/script&gt;</h1>`,
+		`href="https://example.com/p?id=a%26b%20c"`,
+		`href="#ZgotmplZ"`,
+		"<li>“*one*”</li>",
+		"<li>“two”</li>",
+	} {
+		if !strings.Contains(html, e) {
+			t.Errorf("Expected %q in HTML:\n%s", e, html)
+		}
+	}
+	if strings.Contains(html, "<script>") || strings.Contains(html, "<em>") {
+		t.Errorf("Recipient data should not be rendered as HTML or Markdown:\n%s", html)
+	}
+
+	// Plain part is rendered from the same context
+	if _, err := c.MessageFor(0); err != nil {
+		t.Errorf("MessageFor() failed: %s", err)
+	}
+}
@@ -48,7 +48,7 @@ type ctxCampaign struct {
 	Params map[string]interface{}
 
 	// Original subject from frontmatter
-	// before templating via renderSubject
+	// before templating (see Campaign.subjectTemplate)
 	subject string
 
 	// Original "To" from frontmatter
@@ -57,6 +57,16 @@ type ctxCampaign struct {
 
 	// Paths to attachments to each email
 	attachments []string
+
+	// Custom headers (before templating)
+	headers map[string]string
+
+	// SMTP sender, if not "From" (before templating)
+	envelopeFrom string
+
+	// Selected layout and type (see findLayouts)
+	layout string
+	kind   string
 }
 
 func (c ctxCampaign) Subject() string {
@@ -82,7 +92,22 @@ func newCampaign(cfg *config.AConfig, data map[string]interface{}) ctxCampaign {
 		})
 	}
 
+	c.envelopeFrom = cast.ToString(c.Params["envelopefrom"])
+	if c.envelopeFrom == "" {
+		c.envelopeFrom = cfg.EnvelopeFrom
+	}
+
+	c.layout = cast.ToString(c.Params["layout"])
+	c.kind = cast.ToString(c.Params["type"])
+
+	// Header names keep their case (e.g. "Feedback-ID")
+	c.headers = cast.ToStringMapString(c.Params["headers"])
+
 	delete(c.Params, "attachments")
+	delete(c.Params, "headers")
+	delete(c.Params, "envelopefrom")
+	delete(c.Params, "layout")
+	delete(c.Params, "type")
 	delete(c.Params, "subject")
 	delete(c.Params, "from")
 	delete(c.Params, "to")
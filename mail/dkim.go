@@ -13,11 +13,13 @@ import (
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 )
 
-func DKIMMiddleware(ac *config.AConfig) (*dkim.Middleware, error) {
+// DKIMMiddleware signs standard headers, and additional custom headers
+func DKIMMiddleware(ac *config.AConfig, headers ...string) (*dkim.Middleware, error) {
 	conf, appFs := ac.DKIM, ac.AppFs
 
 	// Required: Read private key from keyFile
@@ -36,12 +38,17 @@ func DKIMMiddleware(ac *config.AConfig) (*dkim.Middleware, error) {
 	sel := cast.ToString(conf["selector"])
 
 	// Header fields to sign
-	opts := []dkim.SignerOption{
-		dkim.WithHeaderFields(
-			"Mime-Version", "To", "From", "Subject", "Reply-To",
-			"Sender", "Content-Transfer-Encoding", "Content-Type",
-		),
+	fields := []string{
+		"Mime-Version", "To", "From", "Subject", "Reply-To",
+		"Sender", "Content-Transfer-Encoding", "Content-Type",
+		"List-Unsubscribe", "List-Unsubscribe-Post",
 	}
+	for _, h := range headers {
+		if !slices.ContainsFunc(fields, func(f string) bool { return strings.EqualFold(f, h) }) {
+			fields = append(fields, h)
+		}
+	}
+	opts := []dkim.SignerOption{dkim.WithHeaderFields(fields...)}
 
 	sc, err := dkim.NewConfig(domain, sel, opts...)
 	if err != nil {
@@ -16,6 +16,7 @@ import (
 	"encoding/pem"
 	"fmt"
 	"io"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -114,6 +115,8 @@ func TestDKIMMiddlewareSuccess(t *testing.T) {
 	}
 	msg.Subject("Test Email")
 	msg.SetBodyString(mail.TypeTextPlain, "This is a test email for DKIM signing")
+	msg.SetGenHeader(mail.HeaderListUnsubscribe, "<https://example.com/unsubscribe/abc>")
+	msg.SetGenHeader(mail.HeaderListUnsubscribePost, "List-Unsubscribe=One-Click")
 
 	// Write message and verify DKIM signature is present
 	var buf bytes.Buffer
@@ -135,7 +138,14 @@ func TestDKIMMiddlewareSuccess(t *testing.T) {
 		t.Error("DKIM signature should contain selector (s=default)")
 	}
 
-	verifyEmailWithDKIM(t, &buf, rsaKey)
+	// One-click unsubscribe must be signed (RFC 8058)
+	for _, v := range verifyEmailWithDKIM(t, &buf, rsaKey) {
+		for _, h := range []string{"List-Unsubscribe", "List-Unsubscribe-Post"} {
+			if !slices.Contains(v.HeaderKeys, h) {
+				t.Errorf("DKIM signature should include %s, got %v", h, v.HeaderKeys)
+			}
+		}
+	}
 }
 
 func TestDKIMMiddlewareMissingKeyFile(t *testing.T) {
@@ -231,7 +241,7 @@ func TestMsgOptionsWithDKIM(t *testing.T) {
 	}
 
 	// Test msgOptions function
-	opts, err := msgOptions(cfg)
+	opts, err := msgOptions(cfg, nil)
 	if err != nil {
 		t.Fatalf("Failed to create message options: %v", err)
 	}
@@ -283,7 +293,7 @@ func TestMsgOptionsWithoutDKIM(t *testing.T) {
 	cfg.DKIM = map[string]interface{}{}
 
 	// Test msgOptions function
-	opts, err := msgOptions(cfg)
+	opts, err := msgOptions(cfg, nil)
 	if err != nil {
 		t.Fatalf("Failed to create message options: %v", err)
 	}
@@ -295,7 +305,7 @@ func TestMsgOptionsWithoutDKIM(t *testing.T) {
 }
 
 // Decode and verify DKIM signature for reader of incoming email
-func verifyEmailWithDKIM(t *testing.T, r io.Reader, rsaKey *rsa.PrivateKey) {
+func verifyEmailWithDKIM(t *testing.T, r io.Reader, rsaKey *rsa.PrivateKey) []*dkim.Verification {
 	pubKeyBytes, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
 	if err != nil {
 		t.Fatal(err)
@@ -324,6 +334,7 @@ func verifyEmailWithDKIM(t *testing.T, r io.Reader, rsaKey *rsa.PrivateKey) {
 			t.Errorf("Invalid DKIM signature for domain %s: %s", v.Domain, v.Err)
 		}
 	}
+	return verifications
 }
 
 // Helper function to test DKIM middleware creation and message signing
@@ -409,3 +420,46 @@ func TestNewDkimMiddleware_InvalidKeys(t *testing.T) {
 		}
 	}
 }
+
+func TestDKIMMiddlewareCustomHeaders(t *testing.T) {
+	rsaKey, privateKeyPEM, err := generateTestPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	memFs := afero.NewMemMapFs()
+	afero.WriteFile(memFs, "/dkim/private.key", privateKeyPEM, 0600)
+	cfg, _ := config.LoadConfigFs(t.Context(), memFs)
+	cfg.DKIM = map[string]interface{}{
+		"keyfile":  "/dkim/private.key",
+		"domain":   "example.com",
+		"selector": "default",
+	}
+
+	// Custom headers are signed once
+	middleware, err := DKIMMiddleware(cfg, "Precedence", "X-Campaign-ID", "reply-to")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := mail.NewMsg(mail.WithMiddleware(middleware))
+	msg.From("test@example.com")
+	msg.To("recipient@example.com")
+	msg.SetGenHeader("Precedence", "bulk")
+	msg.SetGenHeader("X-Campaign-ID", "newsletter")
+	msg.SetBodyString(mail.TypeTextPlain, "Hello")
+
+	var buf bytes.Buffer
+	if _, err := msg.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, v := range verifyEmailWithDKIM(t, &buf, rsaKey) {
+		if !slices.Contains(v.HeaderKeys, "Precedence") || !slices.Contains(v.HeaderKeys, "X-Campaign-ID") {
+			t.Errorf("DKIM signature should include custom headers, got %v", v.HeaderKeys)
+		}
+		if n := len(slices.DeleteFunc(slices.Clone(v.HeaderKeys), func(h string) bool { return h != "Reply-To" })); n != 1 {
+			t.Errorf("Expected Reply-To to be signed once, got %v", v.HeaderKeys)
+		}
+	}
+}
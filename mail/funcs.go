@@ -0,0 +1,169 @@
+package mail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	html "html/template"
+	"math"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/spf13/cast"
+)
+
+// Functions shared by subject, "to", header, body and layout
+// templates, with "partial" added for layouts and content
+var templateFuncs = template.FuncMap{
+	// Strings
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"title": titleCase,
+	"trim":  strings.TrimSpace,
+	"join":  joinList,
+
+	// Values
+	"default": defaultValue,
+	"dict":    dict,
+	"list":    list,
+	"first":   first,
+
+	// Dates and numbers
+	"now":          time.Now,
+	"dateFormat":   dateFormat,
+	"formatNumber": formatNumber,
+
+	// HTML and addresses
+	"safeHTML":    safeHTML,
+	"safeURL":     safeURL,
+	"markdownify": markdownify,
+	"verp":        verp,
+}
+
+// Capitalize first letter of every word
+func titleCase(s string) string {
+	prev := ' '
+	return strings.Map(func(r rune) rune {
+		defer func() { prev = r }()
+		if unicode.IsSpace(prev) || prev == '-' {
+			return unicode.ToTitle(r)
+		}
+		return r
+	}, s)
+}
+
+func joinList(sep string, v any) (string, error) {
+	items, err := cast.ToStringSliceE(v)
+	return strings.Join(items, sep), err
+}
+
+// Value, or def if it is empty, as in {{ .Recipient.Name | default "friend" }}
+func defaultValue(def any, given ...any) any {
+	if len(given) == 0 {
+		return def
+	}
+	if truth, ok := template.IsTrue(given[0]); !ok || !truth {
+		return def
+	}
+	return given[0]
+}
+
+// Map of key and value pairs, as in {{ partial "button" (dict "url" $url) }}
+func dict(pairs ...any) (map[string]any, error) {
+	if len(pairs)%2 != 0 {
+		return nil, errors.New("dict expects key and value pairs")
+	}
+
+	out := make(map[string]any, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict key %v is not a string", pairs[i])
+		}
+		out[key] = pairs[i+1]
+	}
+	return out, nil
+}
+
+func list(items ...any) []any {
+	return items
+}
+
+// First n items of a list
+func first(n int, v any) ([]any, error) {
+	items, err := cast.ToSliceE(v)
+	if err != nil {
+		return nil, err
+	}
+	return items[:min(max(n, 0), len(items))], nil
+}
+
+// Format a time, or a date string (e.g. "2025-01-02"), with a Go layout
+func dateFormat(layout string, v any) (string, error) {
+	t, err := cast.ToTimeE(v)
+	if err != nil {
+		return "", err
+	}
+	return t.Format(layout), nil
+}
+
+// Format a number with precision decimals and thousands separators
+func formatNumber(precision int, v any) (string, error) {
+	f, err := cast.ToFloat64E(v)
+	if err != nil {
+		return "", err
+	} else if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Sprint(f), nil
+	}
+
+	s := fmt.Sprintf("%.*f", max(precision, 0), math.Abs(f))
+	whole, frac, _ := strings.Cut(s, ".")
+
+	var out strings.Builder
+	if f < 0 && strings.Trim(s, "0.") != "" {
+		out.WriteByte('-')
+	}
+	for i, d := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			out.WriteByte(',')
+		}
+		out.WriteRune(d)
+	}
+	if frac != "" {
+		out.WriteString("." + frac)
+	}
+	return out.String(), nil
+}
+
+// Trust HTML, which is otherwise escaped in HTML templates
+func safeHTML(v any) html.HTML {
+	return html.HTML(cast.ToString(v))
+}
+
+// Trust URL, which is otherwise filtered in HTML templates (e.g. "tel:")
+func safeURL(v any) html.URL {
+	return html.URL(cast.ToString(v))
+}
+
+// Render Markdown, without the paragraph of a single line
+func markdownify(v any) (html.HTML, error) {
+	var buf bytes.Buffer
+	if err := markdown.Convert([]byte(cast.ToString(v)), &buf); err != nil {
+		return "", err
+	}
+
+	out := ugcPolicy.SanitizeBytes(buf.Bytes())
+	return html.HTML(unwrapParagraph(string(out))), nil
+}
+
+// Remove <p> around a single paragraph
+func unwrapParagraph(s string) string {
+	s = strings.TrimSpace(s)
+	inner, ok := strings.CutPrefix(s, "<p>")
+	if inner, ok2 := strings.CutSuffix(inner, "</p>"); ok && ok2 && !strings.Contains(inner, "<p>") {
+		return inner
+	}
+	return s
+}
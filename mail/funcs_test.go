@@ -0,0 +1,61 @@
+package mail
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	testCases := []struct {
+		tmpl     string
+		expected string
+	}{
+		{`{{ "jane doe" | upper }}`, "JANE DOE"},
+		{`{{ "Jane" | lower }}`, "jane"},
+		{`{{ "jane mary-ann doe" | title }}`, "Jane Mary-Ann Doe"},
+		{`{{ "  x  " | trim }}`, "x"},
+		{`{{ list "a" "b" | join ", " }}`, "a, b"},
+		{`{{ .Missing | default "friend" }}`, "friend"},
+		{`{{ "" | default "friend" }}`, "friend"},
+		{`{{ "Jane" | default "friend" }}`, "Jane"},
+		{`{{ 0 | default 5 }}`, "5"},
+		{`{{ $d := dict "a" 1 "b" "x" }}{{ $d.b }}{{ $d.a }}`, "x1"},
+		{`{{ range first 2 (list 1 2 3) }}{{ . }}{{ end }}`, "12"},
+		{`{{ dateFormat "Jan 2, 2006" "2025-01-02" }}`, "Jan 2, 2025"},
+		{`{{ formatNumber 2 1234567.891 }}`, "1,234,567.89"},
+		{`{{ formatNumber 0 "-1234" }}`, "-1,234"},
+		{`{{ formatNumber 1 999 }}`, "999.0"},
+		{`{{ formatNumber 0 -0.2 }}`, "0"},
+		{`{{ "<b>x</b>" | safeHTML }}`, "<b>x</b>"},
+		{`{{ "**x**" | markdownify }}`, "<strong>x</strong>"},
+		{`{{ verp "jane@example.com" }}`, "jane=example.com"},
+	}
+
+	for _, tc := range testCases {
+		tmpl, err := template.New("test").Funcs(templateFuncs).Parse(tc.tmpl)
+		if err != nil {
+			t.Fatalf("%s: parse failed: %s", tc.tmpl, err)
+		}
+
+		var out strings.Builder
+		if err := tmpl.Execute(&out, map[string]any{}); err != nil {
+			t.Errorf("%s: execute failed: %s", tc.tmpl, err)
+		} else if out.String() != tc.expected {
+			t.Errorf("%s: expected %q, got %q", tc.tmpl, tc.expected, out.String())
+		}
+	}
+
+	// Errors for invalid arguments
+	for _, tmpl := range []string{
+		`{{ dict "a" }}`,
+		`{{ dict 1 2 }}`,
+		`{{ dateFormat "2006" "not a date" }}`,
+		`{{ formatNumber 2 "x" }}`,
+	} {
+		parsed := template.Must(template.New("test").Funcs(templateFuncs).Parse(tmpl))
+		if err := parsed.Execute(&strings.Builder{}, nil); err == nil {
+			t.Errorf("%s: expected an error", tmpl)
+		}
+	}
+}
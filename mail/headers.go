@@ -0,0 +1,125 @@
+package mail
+
+import (
+	"fmt"
+	"maps"
+	netmail "net/mail"
+	"net/textproto"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/wneessen/go-mail"
+)
+
+// Headers set by Paperboy, or from frontmatter (e.g. "to" and "subject")
+var reservedHeaders = []string{
+	"To", "From", "Subject", "Date", "Message-Id", "X-Mailer",
+	"Mime-Version", "Content-Type", "Content-Transfer-Encoding",
+	"Content-Disposition", "List-Unsubscribe", "List-Unsubscribe-Post",
+	"Dkim-Signature", "Return-Path", "Received",
+}
+
+// Headers with a list of addresses, which are also
+// recipients of the message (except for Reply-To)
+var addressHeaders = map[string]mail.AddrHeader{
+	"Reply-To": mail.HeaderReplyTo,
+	"Cc":       mail.HeaderCc,
+	"Bcc":      mail.HeaderBcc,
+}
+
+// Printable ASCII, except colon (RFC 5322)
+var headerNameRegexp = regexp.MustCompile(`^[!-9;-~]+$`)
+
+// Custom header with its value's template
+type headerTemplate struct {
+	name string
+	tmpl *template.Template
+}
+
+// parseHeaders of config and frontmatter (which take precedence) into
+// templates, sorted by name, after validating their names
+func parseHeaders(sources ...map[string]string) ([]headerTemplate, error) {
+	merged := map[string]headerTemplate{}
+	for _, src := range sources {
+		for name, value := range src {
+			name = headerName(name)
+			key := textproto.CanonicalMIMEHeaderKey(name)
+			if !headerNameRegexp.MatchString(name) {
+				return nil, fmt.Errorf("invalid header name %q", name)
+			} else if slices.Contains(reservedHeaders, key) {
+				return nil, fmt.Errorf("header %s is reserved", name)
+			}
+
+			tmpl, err := template.New(name).Funcs(templateFuncs).Parse(value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse header %s: %w", name, err)
+			}
+			merged[key] = headerTemplate{name: name, tmpl: tmpl}
+		}
+	}
+
+	out := make([]headerTemplate, 0, len(merged))
+	for _, key := range slices.Sorted(maps.Keys(merged)) {
+		out = append(out, merged[key])
+	}
+	return out, nil
+}
+
+// Render custom headers into the message, skipping blank ones
+func (c *Campaign) setHeaders(m *mail.Msg, ctx *tmplContext) error {
+	for _, h := range c.headers {
+		value, err := executeTemplate(nil, h.tmpl, ctx)
+		if err != nil {
+			return fmt.Errorf("failed to render header %s: %w", h.name, err)
+		}
+
+		// Folding is up to the mailer, so no line breaks
+		value = strings.Join(strings.Fields(value), " ")
+		if value == "" {
+			continue
+		}
+
+		ah, ok := addressHeaders[textproto.CanonicalMIMEHeaderKey(h.name)]
+		if !ok {
+			m.SetGenHeader(mail.Header(h.name), value)
+			continue
+		}
+
+		addrs, err := netmail.ParseAddressList(value)
+		if err != nil {
+			return fmt.Errorf("invalid addresses in header %s: %w", h.name, err)
+		}
+		m.SetAddrHeaderFromMailAddress(ah, addrs...)
+	}
+	return nil
+}
+
+// Custom headers signed by DKIM, except for Bcc, which is not delivered
+func signedHeaders(headers []headerTemplate) []string {
+	out := []string{}
+	for _, h := range headers {
+		if textproto.CanonicalMIMEHeaderKey(h.name) != "Bcc" {
+			out = append(out, h.name)
+		}
+	}
+	return out
+}
+
+// Bcc recipients are only in the envelope (see send.SupportsBcc)
+func hasBcc(headers []headerTemplate) bool {
+	return slices.ContainsFunc(headers, func(h headerTemplate) bool {
+		return textproto.CanonicalMIMEHeaderKey(h.name) == "Bcc"
+	})
+}
+
+// Config keys are lowercased (e.g. "feedback-id"), while
+// frontmatter keeps the spelling of headers (e.g. "Feedback-ID")
+func headerName(name string) string {
+	name = strings.TrimSpace(name)
+	if name == strings.ToLower(name) {
+		return textproto.CanonicalMIMEHeaderKey(name)
+	}
+	return name
+}
@@ -0,0 +1,119 @@
+package mail
+
+import (
+	"bytes"
+	netmail "net/mail"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/rykov/paperboy/config"
+	"github.com/spf13/afero"
+)
+
+func TestParseHeaders(t *testing.T) {
+	cfgHeaders := map[string]string{"precedence": "bulk", "x-campaign-id": "config"}
+	frontHeaders := map[string]string{"X-Campaign-ID": "front", "Feedback-ID": "{{ .Recipient.email }}"}
+
+	headers, err := parseHeaders(cfgHeaders, frontHeaders)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{}
+	for _, h := range headers {
+		names = append(names, h.name+"="+h.tmpl.Root.String())
+	}
+	expected := []string{"Feedback-ID={{.Recipient.email}}", "Precedence=bulk", "X-Campaign-ID=front"}
+	if !cmp.Equal(names, expected) {
+		t.Errorf("Unexpected headers %v, expected %v", names, expected)
+	}
+
+	// Invalid names, values and reserved headers
+	for _, bad := range []map[string]string{
+		{"from": "x@example.com"},
+		{"Message-ID": "<x@example.com>"},
+		{"List-Unsubscribe": "<https://example.com>"},
+		{"X Space": "value"},
+		{"X-Broken": "{{ .Oops"},
+	} {
+		if _, err := parseHeaders(bad); err == nil {
+			t.Errorf("Expected error for %v", bad)
+		}
+	}
+}
+
+func TestCampaignHeaders(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	afero.WriteFile(fs, "content/c1.md", []byte(`---
+subject: Hello
+headers:
+  Reply-To: "Support <support@example.com>"
+  Cc: "{{ .Recipient.manager }}"
+  Bcc: archive@example.com
+  X-Campaign-ID: "{{ .Subject }}"
+  X-Optional: "{{ with .Recipient.missing }}{{ . }}{{ end }}"
+---
+# Hello`), 0644)
+	afero.WriteFile(fs, "lists/r1.yaml", []byte(`
+- email: jane@example.com
+  manager: boss@example.com
+`), 0644)
+
+	cfg, _ := config.LoadConfigFs(t.Context(), fs)
+	cfg.From = "sender@example.com"
+	cfg.Headers = map[string]string{"precedence": "bulk", "x-campaign-id": "overridden"}
+
+	c, err := LoadCampaign(cfg, "c1", "r1")
+	if err != nil {
+		t.Fatal(err)
+	} else if _, ok := c.EmailMeta.Params["headers"]; ok {
+		t.Errorf("Headers should not be in campaign params")
+	}
+
+	m, err := c.MessageFor(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Cc and Bcc are also recipients
+	rcpts, _ := m.GetRecipients()
+	if exp := []string{"<jane@example.com>", "<boss@example.com>", "<archive@example.com>"}; !cmp.Equal(rcpts, exp) {
+		t.Errorf("Unexpected recipients %v", rcpts)
+	}
+
+	var buf bytes.Buffer
+	if _, err := m.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	msg, err := netmail.ReadMessage(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"Reply-To":      `"Support" <support@example.com>`,
+		"Cc":            "<boss@example.com>",
+		"Bcc":           "",
+		"Precedence":    "bulk",
+		"X-Campaign-Id": "Hello",
+		"X-Optional":    "",
+	}
+	for name, value := range expected {
+		if v := msg.Header.Get(name); v != value {
+			t.Errorf("Expected %s: %q, got %q", name, value, v)
+		}
+	}
+
+	// Raw HTTP transport would drop Bcc
+	cfg.SMTP.URL = "http://127.0.0.1:1/api"
+	if err := SendCampaign(cfg, c); err == nil || !strings.Contains(err.Error(), "Bcc") {
+		t.Errorf("Expected Bcc error, got %v", err)
+	}
+
+	// Invalid address fails rendering
+	c.headers, _ = parseHeaders(map[string]string{"Cc": "not an address"})
+	if _, err := c.MessageFor(0); err == nil || !strings.Contains(err.Error(), "Cc") {
+		t.Errorf("Expected invalid address error, got %v", err)
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"github.com/PuerkitoBio/goquery"
 	"github.com/chris-ramon/douceur/inliner"
-	"github.com/spf13/afero"
 	"golang.org/x/net/html"
 	"path/filepath"
 	"strings"
@@ -32,7 +31,7 @@ func (c *Campaign) inlineStylesheets(layoutPath, body string) (string, error) {
 
 		var cssBytes []byte
 		path := filepath.Join(filepath.Dir(layoutPath), str)
-		if cssBytes, err = afero.ReadFile(c.Config.AppFs, path); err != nil {
+		if cssBytes, err = c.renderer.stylesheet(c.Config.AppFs, path); err != nil {
 			return false
 		}
 
@@ -0,0 +1,75 @@
+package mail
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/rykov/paperboy/config"
+)
+
+// Layout files of a campaign for HTML and plain text,
+// which are blank for the built-in defaults
+type campaignLayouts struct {
+	html string
+	text string
+}
+
+// Find campaign's layouts by name in lookup order, where each name is
+// looked up in project's layouts, then the theme's (see config.Fs.LayoutPath):
+//
+//  1. "layout" of frontmatter: <section>/<layout>, <type>/<layout>, <layout>
+//  2. content subdirectory: <section>/_default
+//  3. "type" of frontmatter: <type>/_default
+//  4. _default
+func findLayouts(fs *config.Fs, tmplID string, meta *ctxCampaign) (campaignLayouts, error) {
+	out := campaignLayouts{}
+	for _, name := range []string{meta.layout, meta.kind} {
+		if name != "" && !filepath.IsLocal(name) {
+			return out, fmt.Errorf("invalid layout %q", name)
+		}
+	}
+
+	// Selected layout is found in one of the formats,
+	// while the other may fall back to a default
+	section := contentSection(tmplID)
+	if l := meta.layout; l != "" {
+		out.find(fs, []string{path.Join(section, l), path.Join(meta.kind, l), l})
+		if out == (campaignLayouts{}) {
+			return out, fmt.Errorf("layout %q not found", l)
+		}
+	}
+
+	var names []string
+	if section != "" {
+		names = append(names, path.Join(section, "_default"))
+	}
+	if meta.kind != "" {
+		names = append(names, path.Join(meta.kind, "_default"))
+	}
+	out.find(fs, append(names, "_default"))
+	return out, nil
+}
+
+// Find missing layouts by the first of names
+func (l *campaignLayouts) find(fs *config.Fs, names []string) {
+	for _, name := range names {
+		if l.html == "" {
+			l.html = fs.LayoutPath(name + ".html")
+		}
+		if l.text == "" {
+			l.text = fs.LayoutPath(name + ".text")
+		}
+	}
+}
+
+// Section of content is its top-level subdirectory (e.g. "news"
+// of "news/2025/weekly"), which is blank at the top level
+func contentSection(tmplID string) string {
+	section, _, ok := strings.Cut(filepath.ToSlash(tmplID), "/")
+	if !ok {
+		return ""
+	}
+	return section
+}
@@ -0,0 +1,76 @@
+package mail
+
+import (
+	"testing"
+
+	"github.com/rykov/paperboy/config"
+	"github.com/spf13/afero"
+)
+
+func TestFindLayouts(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	for _, f := range []string{
+		"layouts/_default.html",
+		"layouts/_default.text",
+		"layouts/announcement.html",
+		"layouts/news/_default.html",
+		"layouts/news/announcement.text",
+		"themes/t1/layouts/digest/_default.html",
+		"themes/t1/layouts/digest/_default.text",
+		"themes/t1/layouts/promo.html",
+		"themes/t1/layouts/news/_default.text",
+	} {
+		afero.WriteFile(memFs, f, []byte("{{ .Content }}"), 0644)
+	}
+
+	cfg, _ := config.LoadConfigFs(t.Context(), memFs)
+	cfg.Theme = "t1"
+
+	testCases := []struct {
+		tmplID, layout, kind string
+		html, text           string
+	}{
+		{"weekly", "", "", "layouts/_default.html", "layouts/_default.text"},
+		{"weekly", "announcement", "", "layouts/announcement.html", "layouts/_default.text"},
+		{"news/weekly", "", "", "layouts/news/_default.html", "themes/t1/layouts/news/_default.text"},
+		{"news/2025/weekly", "announcement", "", "layouts/announcement.html", "layouts/news/announcement.text"},
+		{"weekly", "", "digest", "themes/t1/layouts/digest/_default.html", "themes/t1/layouts/digest/_default.text"},
+		{"news/weekly", "", "digest", "layouts/news/_default.html", "themes/t1/layouts/news/_default.text"},
+		{"weekly", "promo", "digest", "themes/t1/layouts/promo.html", "themes/t1/layouts/digest/_default.text"},
+	}
+
+	for _, tc := range testCases {
+		meta := &ctxCampaign{layout: tc.layout, kind: tc.kind}
+		l, err := findLayouts(cfg.AppFs, tc.tmplID, meta)
+		if err != nil {
+			t.Errorf("%s (%s, %s): %s", tc.tmplID, tc.layout, tc.kind, err)
+		} else if l.html != tc.html || l.text != tc.text {
+			t.Errorf("%s (%s, %s): unexpected layouts %+v", tc.tmplID, tc.layout, tc.kind, l)
+		}
+	}
+
+	for _, layout := range []string{"missing", "../secret"} {
+		if _, err := findLayouts(cfg.AppFs, "weekly", &ctxCampaign{layout: layout}); err == nil {
+			t.Errorf("Expected error for layout %q", layout)
+		}
+	}
+}
+
+func TestCampaignLayoutFrontmatter(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	afero.WriteFile(memFs, "content/news/c1.md", []byte("---\nlayout: announcement\ntype: digest\n---\n# Hello"), 0644)
+	afero.WriteFile(memFs, "layouts/news/announcement.html", []byte("<div>{{ .Content }}</div>"), 0644)
+
+	cfg, _ := config.LoadConfigFs(t.Context(), memFs)
+	c, err := LoadContent(cfg, "news/c1")
+	if err != nil {
+		t.Fatalf("LoadContent() failed: %s", err)
+	}
+
+	if c.renderer.layouts.html != "layouts/news/announcement.html" || c.renderer.layouts.text != "" {
+		t.Errorf("Unexpected layouts: %+v", c.renderer.layouts)
+	}
+	if _, ok := c.EmailMeta.Params["layout"]; ok {
+		t.Errorf("Layout should not be a param: %+v", c.EmailMeta.Params)
+	}
+}
@@ -0,0 +1,101 @@
+package mail
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// ReadMailbox calls fn with every message of a Maildir, a directory
+// of message files, an mbox, or a single message file at path, in order
+func ReadMailbox(fs afero.Fs, path string, fn func(io.Reader) error) error {
+	info, err := fs.Stat(path)
+	if err != nil {
+		return err
+	} else if !info.IsDir() {
+		file, err := fs.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		return readMbox(file, fn)
+	}
+
+	// Maildir keeps messages in "new" and "cur"
+	dirs := []string{path}
+	if ok, _ := afero.DirExists(fs, filepath.Join(path, "cur")); ok {
+		dirs = []string{filepath.Join(path, "new"), filepath.Join(path, "cur")}
+	}
+
+	for _, dir := range dirs {
+		entries, err := afero.ReadDir(fs, dir)
+		if err != nil && dir != path {
+			continue // Maildir without "new"
+		} else if err != nil {
+			return err
+		}
+
+		for _, e := range entries {
+			if e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+				continue
+			}
+			if err := readMessageFile(fs, filepath.Join(dir, e.Name()), fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func readMessageFile(fs afero.Fs, path string, fn func(io.Reader) error) error {
+	file, err := fs.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return fn(file)
+}
+
+// Split mbox on "From " lines, and unescape ">From " (mboxrd),
+// or read a single message, which does not start with "From "
+func readMbox(r io.Reader, fn func(io.Reader) error) error {
+	br := bufio.NewReader(r)
+	if peek, _ := br.Peek(5); string(peek) != "From " {
+		return fn(br)
+	}
+
+	var msg bytes.Buffer
+	started := false
+	flush := func() error {
+		if !started {
+			return nil
+		}
+		defer msg.Reset()
+		return fn(bytes.NewReader(msg.Bytes()))
+	}
+
+	for {
+		line, err := br.ReadString('\n')
+		if strings.HasPrefix(line, "From ") {
+			if ferr := flush(); ferr != nil {
+				return ferr
+			}
+			started = true
+		} else if started && line != "" {
+			if unquoted := strings.TrimLeft(line, ">"); len(unquoted) < len(line) && strings.HasPrefix(unquoted, "From ") {
+				line = line[1:]
+			}
+			msg.WriteString(line)
+		}
+
+		if err == io.EOF {
+			return flush()
+		} else if err != nil {
+			return err
+		}
+	}
+}
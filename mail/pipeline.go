@@ -0,0 +1,79 @@
+package mail
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/wneessen/go-mail"
+)
+
+// Messages rendered ahead of delivery, per render worker
+const renderAhead = 2
+
+type renderJob struct {
+	i   int
+	out chan renderResult
+}
+
+type renderResult struct {
+	msg *mail.Msg
+	err error
+}
+
+// Render messages of all recipients with a pool of workers, and pass
+// them to fn in recipient order, so deliveries are logged in list order.
+// Only a few messages per worker are rendered ahead of fn, which keeps
+// memory bounded when delivery is slower than rendering.
+func renderMessages(c *Campaign, workers int, fn func(i int, m *mail.Msg) error) error {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	// Stops dispatching, once fn or rendering fails
+	stop := make(chan struct{})
+	defer close(stop)
+
+	// Results in recipient order, which bounds messages in flight
+	jobs := make(chan renderJob)
+	pending := make(chan chan renderResult, workers*renderAhead)
+
+	for range workers {
+		go func() {
+			for j := range jobs {
+				m, err := c.MessageFor(j.i)
+				j.out <- renderResult{m, err}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(pending)
+		defer close(jobs)
+		for i := range c.Recipients {
+			out := make(chan renderResult, 1)
+			select {
+			case pending <- out:
+			case <-stop:
+				return
+			}
+			select {
+			case jobs <- renderJob{i, out}:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	i := 0
+	for out := range pending {
+		r := <-out
+		if r.err != nil {
+			return fmt.Errorf("could not render email for recipient %d: %w", c.listPosition(i), r.err)
+		}
+		if err := fn(i, r.msg); err != nil {
+			return err
+		}
+		i++
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package mail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/rykov/paperboy/config"
+	"github.com/spf13/afero"
+	"github.com/wneessen/go-mail"
+)
+
+func TestRenderMessages(t *testing.T) {
+	c := newTestRenderCampaign(t, afero.NewMemMapFs(), 50)
+
+	for _, workers := range []int{0, 1, 4} {
+		var got []int
+		err := renderMessages(c, workers, func(i int, m *mail.Msg) error {
+			var buf bytes.Buffer
+			m.WriteTo(&buf)
+			if e := fmt.Sprintf("Hello user%d", i); !strings.Contains(buf.String(), e) {
+				t.Errorf("Expected %q in message %d", e, i)
+			}
+			got = append(got, i)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("renderMessages(%d) failed: %s", workers, err)
+		}
+		for i, g := range got {
+			if g != i {
+				t.Fatalf("Workers %d: out of order at %d: %v", workers, i, got)
+			}
+		}
+		if len(got) != len(c.Recipients) {
+			t.Errorf("Workers %d: expected %d messages, got %d", workers, len(c.Recipients), len(got))
+		}
+	}
+}
+
+func TestRenderMessagesErrors(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	afero.WriteFile(memFs, "content/c1.md", []byte("Since {{ dateFormat \"2006\" .Recipient.since }}"), 0644)
+
+	cfg, _ := config.LoadConfigFs(t.Context(), memFs)
+	cfg.From = "news@example.com"
+	c, err := LoadContent(cfg, "c1")
+	if err != nil {
+		t.Fatalf("LoadContent() failed: %s", err)
+	}
+
+	data := make([]map[string]any, 20)
+	for i := range data {
+		data[i] = map[string]any{"email": fmt.Sprintf("user%d@example.com", i), "since": "2020-01-02"}
+	}
+	data[7]["since"] = "never"
+	c.Recipients, _ = MapsToRecipients(data)
+
+	// First list entry was suppressed
+	c.positions = make([]int, len(c.Recipients))
+	for i := range c.positions {
+		c.positions[i] = i + 1
+	}
+
+	// Rendering error stops at its recipient, by list position
+	calls := 0
+	err = renderMessages(c, 4, func(i int, m *mail.Msg) error {
+		calls++
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "recipient 8") {
+		t.Errorf("Expected render error for recipient 8, got %v", err)
+	} else if calls != 7 {
+		t.Errorf("Expected 7 messages before error, got %d", calls)
+	}
+
+	// Error of fn stops rendering
+	stop := errors.New("stop")
+	calls = 0
+	err = renderMessages(c, 4, func(i int, m *mail.Msg) error {
+		if calls++; i == 3 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) || calls != 4 {
+		t.Errorf("Expected to stop after 4 messages, got %d (%v)", calls, err)
+	}
+}
+
+// Compare throughput of rendering in a loop and with render workers
+func BenchmarkRenderMessages(b *testing.B) {
+	c := newTestRenderCampaign(b, afero.NewMemMapFs(), 100)
+
+	b.Run("serial", func(b *testing.B) {
+		for b.Loop() {
+			for i := range c.Recipients {
+				if _, err := c.MessageFor(i); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	b.Run("workers", func(b *testing.B) {
+		for b.Loop() {
+			err := renderMessages(c, 0, func(int, *mail.Msg) error { return nil })
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
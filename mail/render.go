@@ -0,0 +1,153 @@
+package mail
+
+import (
+	"bytes"
+	"path/filepath"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/spf13/afero"
+)
+
+// Sanitizers are safe for concurrent use
+var (
+	strictPolicy = bluemonday.StrictPolicy()
+	ugcPolicy    = bluemonday.UGCPolicy()
+)
+
+// Render pipeline of a campaign, which is compiled once in
+// LoadContent, and shared by all recipients (and workers)
+type renderer struct {
+	layouts    campaignLayouts
+	textLayout Template
+	htmlLayout Template
+
+	// Markdown to plain text renderers, which
+	// are not safe for concurrent use
+	terms sync.Pool
+
+	// Plain text of the last content, which is the same for
+	// all recipients, unless it has their data (e.g. name)
+	plain memo
+
+	// Contents of stylesheets by path
+	lock        sync.Mutex
+	stylesheets map[string][]byte
+}
+
+func compileRenderer(fs afero.Fs, layouts campaignLayouts, text, html *templateLibrary) (*renderer, error) {
+	textLayout, err := text.load(layouts.text, "{{ .Content }}")
+	if err != nil {
+		return nil, err
+	}
+
+	htmlLayout, err := html.load(layouts.html, "<html><body>{{ .Content }}</body></html>")
+	if err != nil {
+		return nil, err
+	}
+
+	// Bail on a broken Markdown renderer early
+	term, err := newTermRenderer()
+	if err != nil {
+		return nil, err
+	}
+
+	r := &renderer{
+		layouts:     layouts,
+		textLayout:  textLayout,
+		htmlLayout:  htmlLayout,
+		stylesheets: map[string][]byte{},
+	}
+	r.terms.New = func() any {
+		term, _ := newTermRenderer()
+		return term
+	}
+	r.terms.Put(term)
+
+	// Preload stylesheets of the layout (see inlineStylesheets)
+	if path := layouts.html; path != "" {
+		raw, err := afero.ReadFile(fs, path)
+		if err != nil {
+			return nil, err
+		}
+		doc, err := goquery.NewDocumentFromReader(bytes.NewReader(raw))
+		if err != nil {
+			return nil, err
+		}
+		doc.Find("link[rel=stylesheet][href]").Each(func(_ int, s *goquery.Selection) {
+			href, _ := s.Attr("href")
+			r.stylesheet(fs, filepath.Join(filepath.Dir(path), href))
+		})
+	}
+	return r, nil
+}
+
+// Render Markdown to plain text, without any HTML
+func (r *renderer) markdownToText(md string) (string, error) {
+	term := r.terms.Get().(*glamour.TermRenderer)
+	defer r.terms.Put(term)
+
+	out, err := term.RenderBytes([]byte(md))
+	if err != nil {
+		return "", err
+	}
+	return string(strictPolicy.SanitizeBytes(out)), nil
+}
+
+// Stylesheet of a layout, which is read once,
+// unless the campaign is not compiled
+func (r *renderer) stylesheet(fs afero.Fs, path string) ([]byte, error) {
+	if r == nil {
+		return afero.ReadFile(fs, path)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if css, ok := r.stylesheets[path]; ok {
+		return css, nil
+	}
+
+	css, err := afero.ReadFile(fs, path)
+	if err == nil {
+		r.stylesheets[path] = css
+	}
+	return css, err
+}
+
+// For markdown, we use notty style with tweaks
+func newTermRenderer() (*glamour.TermRenderer, error) {
+	style := *styles.DefaultStyles["notty"]
+	style.Link.BlockPrefix = "("
+	style.Link.BlockSuffix = ")"
+	return glamour.NewTermRenderer(glamour.WithStyles(style), glamour.WithWordWrap(-1))
+}
+
+// Single-entry cache of a rendering step, which
+// skips rendering the same input again
+type memo struct {
+	lock    sync.Mutex
+	ok      bool
+	in, out string
+}
+
+func (m *memo) do(in string, fn func(string) (string, error)) (string, error) {
+	m.lock.Lock()
+	if m.ok && m.in == in {
+		defer m.lock.Unlock()
+		return m.out, nil
+	}
+	m.lock.Unlock()
+
+	out, err := fn(in)
+	if err != nil {
+		return "", err
+	}
+
+	m.lock.Lock()
+	m.ok, m.in, m.out = true, in, out
+	m.lock.Unlock()
+	return out, nil
+}
@@ -0,0 +1,109 @@
+package mail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/rykov/paperboy/config"
+	"github.com/spf13/afero"
+)
+
+func TestMemo(t *testing.T) {
+	var m memo
+	calls := 0
+	upper := func(s string) (string, error) {
+		calls++
+		if s == "" {
+			return "", errors.New("empty")
+		}
+		return strings.ToUpper(s), nil
+	}
+
+	for _, in := range []string{"a", "a", "b", "a", "", "a"} {
+		out, err := m.do(in, upper)
+		if in == "" && err == nil {
+			t.Errorf("Expected error for empty input")
+		} else if in != "" && out != strings.ToUpper(in) {
+			t.Errorf("Unexpected output for %q: %q", in, out)
+		}
+	}
+	if calls != 4 {
+		t.Errorf("Expected 4 calls, got %d", calls)
+	}
+}
+
+func TestCompiledRenderer(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	c := newTestRenderCampaign(t, memFs, 20)
+
+	// Layouts and stylesheets are not read again
+	afero.WriteFile(memFs, "layouts/_default.html", []byte("changed"), 0644)
+	afero.WriteFile(memFs, "layouts/_default.text", []byte("changed"), 0644)
+	afero.WriteFile(memFs, "layouts/style.css", []byte("p { color: blue }"), 0644)
+
+	// Render in parallel, as delivery workers do
+	out := make([]string, len(c.Recipients))
+	var wg sync.WaitGroup
+	for i := range c.Recipients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m, err := c.MessageFor(i)
+			if err != nil {
+				t.Errorf("MessageFor(%d) failed: %s", i, err)
+				return
+			}
+			var buf bytes.Buffer
+			m.WriteTo(&buf)
+			out[i] = buf.String()
+		}()
+	}
+	wg.Wait()
+
+	for i, msg := range out {
+		for _, e := range []string{
+			fmt.Sprintf("Hello user%d", i),
+			`style=3D"color: red;"`,
+			"Static footer",
+		} {
+			if !strings.Contains(msg, e) {
+				t.Errorf("Expected %q in message %d:\n%s", e, i, msg)
+			}
+		}
+	}
+}
+
+func BenchmarkMessageFor(b *testing.B) {
+	c := newTestRenderCampaign(b, afero.NewMemMapFs(), 100)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.MessageFor(i % len(c.Recipients)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func newTestRenderCampaign(tb testing.TB, memFs afero.Fs, n int) *Campaign {
+	afero.WriteFile(memFs, "content/c1.md", []byte("---\nsubject: Hi\n---\n# Hello {{ .Recipient.Name }}\n\nSome *static* text.\n"), 0644)
+	afero.WriteFile(memFs, "layouts/_default.html", []byte(`<html><head><link rel="stylesheet" href="style.css"></head><body>{{ .Content }}<p>Static footer</p></body></html>`), 0644)
+	afero.WriteFile(memFs, "layouts/_default.text", []byte("{{ .Content }}\nStatic footer"), 0644)
+	afero.WriteFile(memFs, "layouts/style.css", []byte("p { color: red }"), 0644)
+
+	cfg, _ := config.LoadConfigFs(tb.Context(), memFs)
+	cfg.From = "news@example.com"
+	c, err := LoadContent(cfg, "c1")
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	data := make([]map[string]any, n)
+	for i := range data {
+		data[i] = map[string]any{"email": fmt.Sprintf("user%d@example.com", i), "name": fmt.Sprintf("user%d", i)}
+	}
+	c.Recipients, _ = MapsToRecipients(data)
+	return c
+}
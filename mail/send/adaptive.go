@@ -0,0 +1,82 @@
+package send
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Adaptive send rate, backing off when the remote
+// server is throttling us (e.g. 421 or 4.7.x replies)
+type AdaptiveConfig struct {
+	Enabled bool
+	MinRate float32 // Messages per second while backing off
+}
+
+// Interval to start backing off from without a send rate
+const minBackoffInterval = 100 * time.Millisecond
+
+// Share of the slowdown recovered with each successful delivery
+const adaptiveRecovery = 8
+
+// adaptiveRate is the interval between messages that doubles
+// on remote throttling and gradually recovers with deliveries
+type adaptiveRate struct {
+	base     time.Duration
+	max      time.Duration
+	current  time.Duration
+	adaptive bool
+	lock     sync.Mutex
+}
+
+func newAdaptiveRate(rate float32, cfg AdaptiveConfig) *adaptiveRate {
+	a := &adaptiveRate{adaptive: cfg.Enabled}
+	if rate > 0 {
+		a.base = time.Duration(float64(time.Second) / float64(rate))
+	}
+	if cfg.MinRate > 0 {
+		a.max = time.Duration(float64(time.Second) / float64(cfg.MinRate))
+	}
+	a.current = a.base
+	return a
+}
+
+// interval returns the current delay between messages
+func (a *adaptiveRate) interval() time.Duration {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return a.current
+}
+
+// throttled slows down the rate, returning the new interval
+func (a *adaptiveRate) throttled() time.Duration {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.adaptive {
+		a.current = max(a.current*2, minBackoffInterval)
+		if a.max > 0 {
+			a.current = min(a.current, max(a.max, a.base))
+		}
+	}
+
+	return a.current
+}
+
+// succeeded recovers some of the slowdown
+func (a *adaptiveRate) succeeded() {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if slowdown := a.current - a.base; slowdown < time.Millisecond {
+		a.current = a.base
+	} else {
+		a.current -= slowdown / adaptiveRecovery
+	}
+}
+
+// isThrottled detects "too many messages" replies,
+// such as 421 (service unavailable) or 4.7.x statuses
+func isThrottled(r *Result) bool {
+	return r.Code == 421 || strings.HasPrefix(r.Status, "4.7.")
+}
@@ -0,0 +1,66 @@
+package send
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAdaptiveRate(t *testing.T) {
+	a := newAdaptiveRate(10, AdaptiveConfig{Enabled: true, MinRate: 2})
+	if i := a.interval(); i != 100*time.Millisecond {
+		t.Fatalf("Unexpected initial interval: %s", i)
+	}
+
+	if i := a.throttled(); i != 200*time.Millisecond {
+		t.Errorf("Expected doubled interval, got %s", i)
+	}
+	a.throttled()
+	if i := a.throttled(); i != 500*time.Millisecond {
+		t.Errorf("Expected interval capped by MinRate, got %s", i)
+	}
+
+	a.succeeded()
+	if i := a.interval(); i != 450*time.Millisecond {
+		t.Errorf("Expected partial recovery, got %s", i)
+	}
+	for range 100 {
+		a.succeeded()
+	}
+	if i := a.interval(); i != 100*time.Millisecond {
+		t.Errorf("Expected full recovery, got %s", i)
+	}
+}
+
+func TestAdaptiveRate_Unlimited(t *testing.T) {
+	a := newAdaptiveRate(0, AdaptiveConfig{Enabled: true})
+	if i := a.throttled(); i != minBackoffInterval {
+		t.Errorf("Expected minimum backoff, got %s", i)
+	}
+
+	a = newAdaptiveRate(0, AdaptiveConfig{Enabled: false})
+	if i := a.throttled(); i != 0 {
+		t.Errorf("Should not back off when disabled, got %s", i)
+	}
+}
+
+func TestIsThrottled(t *testing.T) {
+	cases := []struct {
+		code      int
+		status    string
+		throttled bool
+	}{
+		{421, "", true},
+		{421, "4.7.0", true},
+		{450, "4.7.28", true},
+		{451, "4.3.0", false},
+		{550, "5.7.1", false},
+		{0, "", false},
+	}
+
+	for _, c := range cases {
+		r := &Result{Code: c.code, Status: c.status}
+		if th := isThrottled(r); th != c.throttled {
+			t.Errorf("isThrottled(%d %s) = %t, expected %t", c.code, c.status, th, c.throttled)
+		}
+	}
+}
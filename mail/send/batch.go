@@ -0,0 +1,67 @@
+package send
+
+import (
+	"errors"
+
+	"github.com/wneessen/go-mail"
+)
+
+// BatchError holds errors of a multi-message Conn.Send,
+// with a nil error for each delivered message
+type BatchError []error
+
+func (e BatchError) Error() string {
+	return errors.Join(e...).Error()
+}
+
+func (e BatchError) Unwrap() []error {
+	out := make([]error, 0, len(e))
+	for _, err := range e {
+		if err != nil {
+			out = append(out, err)
+		}
+	}
+	return out
+}
+
+// sendEach delivers messages one by one, and returns
+// a BatchError for multiple messages if any failed
+func sendEach(msgs []*mail.Msg, send func(*mail.Msg) error) error {
+	errs, failed := make(BatchError, len(msgs)), false
+	for i, msg := range msgs {
+		if errs[i] = send(msg); errs[i] != nil {
+			failed = true
+		}
+	}
+
+	if !failed {
+		return nil
+	} else if len(errs) == 1 {
+		return errs[0]
+	}
+	return errs
+}
+
+// messageError attributes the error of a batch Send to its i-th message
+func messageError(err error, msgs []*mail.Msg, i int) error {
+	if err == nil || len(msgs) == 1 {
+		return err
+	}
+
+	var batchErr BatchError
+	if errors.As(err, &batchErr) && len(batchErr) == len(msgs) {
+		return batchErr[i]
+	}
+
+	// SMTP client reports on each message, unless the connection failed
+	var sendErr *mail.SendError
+	if msgs[i].IsDelivered() {
+		return nil
+	} else if errors.As(err, &sendErr) && sendErr.Reason == mail.ErrConnCheck {
+		return err
+	} else if msgs[i].HasSendError() {
+		return msgs[i].SendError()
+	}
+
+	return err
+}
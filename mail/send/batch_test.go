@@ -0,0 +1,126 @@
+package send
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/wneessen/go-mail"
+)
+
+func TestMessageError(t *testing.T) {
+	msgs := []*mail.Msg{mail.NewMsg(), mail.NewMsg()}
+	failure := errors.New("rejected")
+
+	cases := []struct {
+		name     string
+		err      error
+		expected []error
+	}{
+		{"Success", nil, []error{nil, nil}},
+		{"Batch error", BatchError{nil, failure}, []error{nil, failure}},
+		{"Connection error", failure, []error{failure, failure}},
+	}
+
+	for _, c := range cases {
+		for i := range msgs {
+			if err := messageError(c.err, msgs, i); err != c.expected[i] {
+				t.Errorf("%s: message %d error is %v, expected %v", c.name, i, err, c.expected[i])
+			}
+		}
+	}
+
+	// Single message keeps the error as-is
+	if err := messageError(BatchError{failure}, msgs[:1], 0); !errors.Is(err, failure) {
+		t.Errorf("Unexpected single message error: %v", err)
+	}
+}
+
+func TestSendEach(t *testing.T) {
+	msgs := []*mail.Msg{mail.NewMsg(), mail.NewMsg(), mail.NewMsg()}
+	err := sendEach(msgs, func(msg *mail.Msg) error {
+		if msg == msgs[1] {
+			return errors.New("failed")
+		}
+		return nil
+	})
+
+	var batchErr BatchError
+	if !errors.As(err, &batchErr) || len(batchErr) != 3 || batchErr[1] == nil || batchErr[0] != nil {
+		t.Errorf("Unexpected batch error: %#v", err)
+	}
+	if err := sendEach(msgs, func(*mail.Msg) error { return nil }); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+func TestMemoryQueue_Batches(t *testing.T) {
+	var mu sync.Mutex
+	var sizes []int
+	var conns atomic.Int32
+	sender := &mockSender{connFunc: func() (Conn, error) {
+		conns.Add(1)
+		return &mockConn{sendFunc: func(msgs ...*mail.Msg) error {
+			mu.Lock()
+			sizes = append(sizes, len(msgs))
+			mu.Unlock()
+			time.Sleep(20 * time.Millisecond) // Let the batch fill up
+			return nil
+		}}, nil
+	}}
+
+	if err := runQueue(t, &Config{Workers: 1, BatchSize: 4}, sender, testRecipients(10)); err != nil {
+		t.Fatalf("Wait() failed: %v", err)
+	} else if n := conns.Load(); n != 1 {
+		t.Errorf("Expected a single connection, got %d", n)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	total, largest := 0, 0
+	for _, s := range sizes {
+		total, largest = total+s, max(largest, s)
+	}
+	if total != 10 {
+		t.Errorf("Expected 10 messages sent, got %d in %v", total, sizes)
+	}
+	if largest < 2 || largest > 4 {
+		t.Errorf("Expected batches of up to 4 messages, got %v", sizes)
+	}
+}
+
+func TestMemoryQueue_SessionLimit(t *testing.T) {
+	// Messages sent by each session
+	var mu sync.Mutex
+	var sessions []int
+	sender := &mockSender{connFunc: func() (Conn, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		id := len(sessions)
+		sessions = append(sessions, 0)
+		return &mockConn{sendFunc: func(msgs ...*mail.Msg) error {
+			mu.Lock()
+			sessions[id] += len(msgs)
+			mu.Unlock()
+			return nil
+		}}, nil
+	}}
+
+	cfg := &Config{Workers: 1, BatchSize: 2, SessionLimit: 3}
+	if err := runQueue(t, cfg, sender, testRecipients(7)); err != nil {
+		t.Fatalf("Wait() failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(sessions) != 3 {
+		t.Errorf("Expected 3 sessions for 7 messages, got %d", len(sessions))
+	}
+	for id, n := range sessions {
+		if n > 3 {
+			t.Errorf("Session %d sent %d messages, above the limit", id, n)
+		}
+	}
+}
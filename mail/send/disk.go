@@ -0,0 +1,186 @@
+package send
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/wneessen/go-mail"
+)
+
+// diskQueue is a channel-based queue that records the delivery state of
+// every message into a journal, so an interrupted delivery can be resumed
+type diskQueue struct {
+	*memoryQueue
+	journal *journal
+}
+
+// NewOnDisk creates a queue that persists delivery state to cfg.StateFile.
+// With cfg.Resume, recipients acknowledged in a previous run are skipped.
+func NewOnDisk(ctx context.Context, cfg *Config, sender Sender, fs afero.Fs) (Manager, error) {
+	if cfg.StateFile == "" {
+		return nil, errors.New("disk queue requires a state file")
+	}
+
+	j, err := openJournal(fs, cfg.StateFile, cfg.Resume)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open delivery state: %w", err)
+	}
+
+	if cfg.Resume {
+		fmt.Printf("Resuming %s with %d delivered recipients\n", cfg.QueueID, len(j.sent))
+	}
+
+	// Journal records outcomes before other reporters
+	qc := *cfg
+	qc.Reporters = append([]Reporter{j}, cfg.Reporters...)
+	queue, err := newMemoryQueue(ctx, &qc, sender)
+	if err != nil {
+		j.Close()
+		return nil, err
+	}
+
+	return &diskQueue{memoryQueue: queue, journal: j}, nil
+}
+
+// Enqueue records and adds an email message to the queue,
+// unless it was already delivered in a previous run
+func (d *diskQueue) Enqueue(ctx context.Context, msg *mail.Msg) error {
+	t := newTask(ctx, msg)
+	if d.journal.delivered(t) {
+		fmt.Printf("Skipping %s to %s (already delivered)\n", d.ID, t.recipient())
+		d.job.enqueued(true)
+		return nil
+	}
+
+	entry := journalEntry{Index: t.index, Email: t.recipient(), State: StateQueued}
+	if err := d.journal.record(&entry); err != nil {
+		return err
+	}
+
+	return d.memoryQueue.Enqueue(ctx, msg)
+}
+
+// Wait blocks until all queued tasks are completed and the state is saved
+func (d *diskQueue) Wait() error {
+	errW := d.memoryQueue.Wait()
+	return errors.Join(errW, d.journal.Close())
+}
+
+// journal is an append-only JSONL log of delivery states
+type journal struct {
+	file afero.File
+	enc  *json.Encoder
+	lock sync.Mutex
+
+	// Recipients delivered in previous runs (index -> email)
+	sent map[int]string
+
+	closed   sync.Once
+	closeErr error
+}
+
+type journalEntry struct {
+	Index int       `json:"index"`
+	Email string    `json:"email"`
+	State string    `json:"state"`
+	Error string    `json:"error,omitempty"`
+	Time  time.Time `json:"time"`
+}
+
+func openJournal(fs afero.Fs, path string, resume bool) (*journal, error) {
+	if err := fs.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	j := &journal{sent: map[int]string{}}
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if resume {
+		if err := j.load(fs, path); err != nil {
+			return nil, err
+		}
+	} else {
+		flags |= os.O_TRUNC
+	}
+
+	file, err := fs.OpenFile(path, flags, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	j.file, j.enc = file, json.NewEncoder(file)
+	return j, nil
+}
+
+// Replay existing journal, the latest state of each recipient wins
+func (j *journal) load(fs afero.Fs, path string) error {
+	file, err := fs.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var e journalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue // Partial write from a crash
+		}
+		if e.State == StateSent {
+			j.sent[e.Index] = e.Email
+		} else {
+			delete(j.sent, e.Index)
+		}
+	}
+
+	return scanner.Err()
+}
+
+// Recipient was delivered previously (at the same index)
+func (j *journal) delivered(t *task) bool {
+	if t.index < 0 {
+		return false
+	}
+	email, ok := j.sent[t.index]
+	return ok && email == t.recipient()
+}
+
+func (j *journal) record(e *journalEntry) error {
+	if e.Index < 0 {
+		return nil // Not resumable
+	}
+
+	e.Time = time.Now()
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	return j.enc.Encode(e)
+}
+
+// Report records delivery outcomes from memoryQueue workers
+func (j *journal) Report(r *Result) {
+	e := journalEntry{Index: r.Index, Email: r.Email, State: r.State}
+	if r.Failed() {
+		e.Error = r.Response
+	}
+	if err := j.record(&e); err != nil {
+		fmt.Printf("Could not record delivery state: %s\n", err)
+	}
+}
+
+func (j *journal) Close() error {
+	j.closed.Do(func() {
+		j.lock.Lock()
+		defer j.lock.Unlock()
+		j.closeErr = j.file.Close()
+	})
+	return j.closeErr
+}
@@ -0,0 +1,119 @@
+package send
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/wneessen/go-mail"
+)
+
+func TestNewOnDisk_NoStateFile(t *testing.T) {
+	cfg := &Config{QueueID: "test-campaign", Workers: 1}
+	queue, err := NewOnDisk(context.Background(), cfg, &mockSender{}, afero.NewMemMapFs())
+	if err == nil {
+		t.Fatal("NewOnDisk() should fail without a state file")
+	}
+	if queue != nil {
+		t.Error("NewOnDisk() should return nil queue on error")
+	}
+}
+
+func TestDiskQueue_Resume(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	emails := []string{"a@example.com", "b@example.com", "c@example.com"}
+	runDiskQueue := func(resume bool, failFor string) ([]string, error) {
+		sender, sent := rejectingSender(failFor)
+		err := runQueue(t, diskConfig(resume), sender, emails, fs)
+		return sent(), err
+	}
+
+	// Deliver all, with one recipient failing
+	var dErr *DeliveryError
+	sent, err := runDiskQueue(false, "b@example.com")
+	if !errors.As(err, &dErr) {
+		t.Fatalf("Wait() should fail with DeliveryError, got: %v", err)
+	} else if d := strings.Join(sent, ","); d != "a@example.com,c@example.com" {
+		t.Fatalf("Unexpected first run deliveries: %s", d)
+	}
+
+	// Journal should contain queued/sent/failed states
+	raw, err := afero.ReadFile(fs, "state/test.jsonl")
+	if err != nil {
+		t.Fatalf("Could not read state: %s", err)
+	}
+	for _, s := range []string{`"state":"queued"`, `"state":"sent"`, `"state":"failed"`} {
+		if !strings.Contains(string(raw), s) {
+			t.Errorf("State should contain %s: %s", s, raw)
+		}
+	}
+
+	// Resume should only deliver the failed recipient
+	sent, _ = runDiskQueue(true, "")
+	if d := strings.Join(sent, ","); d != "b@example.com" {
+		t.Fatalf("Unexpected resumed deliveries: %s", d)
+	}
+
+	// Nothing left to resume
+	if sent, _ = runDiskQueue(true, ""); len(sent) != 0 {
+		t.Fatalf("Unexpected deliveries after completion: %v", sent)
+	}
+
+	// Fresh run ignores the previous state
+	if sent, _ = runDiskQueue(false, ""); len(sent) != 3 {
+		t.Fatalf("Expected 3 deliveries without resume, got %v", sent)
+	}
+
+	// Changed list at the same index is not considered delivered
+	emails[0] = "z@example.com"
+	sent, _ = runDiskQueue(true, "")
+	if d := strings.Join(sent, ","); d != "z@example.com" {
+		t.Fatalf("Unexpected deliveries after list change: %s", d)
+	}
+}
+
+func TestDiskQueue_ResumeIgnoresPartialEntries(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	afero.WriteFile(fs, "state/test.jsonl", []byte(
+		`{"index":0,"email":"a@example.com","state":"sent"}`+"\n"+
+			`{"index":1,"email":"b@exa`,
+	), 0644)
+
+	sender, sent := rejectingSender("")
+	if err := runQueue(t, diskConfig(true), sender, []string{"a@example.com", "b@example.com"}, fs); err != nil {
+		t.Fatalf("Wait() failed: %v", err)
+	} else if d := strings.Join(sent(), ","); d != "b@example.com" {
+		t.Fatalf("Unexpected resumed deliveries: %s", d)
+	}
+}
+
+// Journaled queue of disk tests
+func diskConfig(resume bool) *Config {
+	return &Config{QueueID: "test", Workers: 1, StateFile: "state/test.jsonl", Resume: resume}
+}
+
+// Sender of a single connection, which fails to send to failFor,
+// and returns recipients of its sent messages
+func rejectingSender(failFor string) (Sender, func() []string) {
+	conn := &mockConn{}
+	conn.sendFunc = func(msgs ...*mail.Msg) error {
+		for _, m := range msgs {
+			if m.GetTo()[0].Address == failFor {
+				return errors.New("send failed")
+			}
+			conn.sent = append(conn.sent, m)
+		}
+		return nil
+	}
+
+	sender := &mockSender{connFunc: func() (Conn, error) { return conn, nil }}
+	return sender, func() []string {
+		out := []string{}
+		for _, m := range conn.getSent() {
+			out = append(out, m.GetTo()[0].Address)
+		}
+		return out
+	}
+}
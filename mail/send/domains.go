@@ -0,0 +1,133 @@
+package send
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Delivery limits for recipients of a domain, or a group of
+// domains sharing the same provider (e.g. Outlook and Hotmail)
+type DomainConfig struct {
+	Match   []string // Domains in the group (defaults to its key)
+	Rate    float32  // Messages per second
+	Workers int      // Concurrent deliveries
+}
+
+// domainLimit is the scheduling state shared by a domain group
+type domainLimit struct {
+	name    string
+	rate    *adaptiveRate // Unless falling back to global rate
+	workers int
+
+	// Guarded by memoryQueue.schedL
+	next    time.Time
+	active  int
+	waiting []*task
+}
+
+// domainLimits maps lowercase domains to their limits
+type domainLimits map[string]*domainLimit
+
+func newDomainLimits(cfg map[string]DomainConfig, ac AdaptiveConfig) domainLimits {
+	out := domainLimits{}
+	for name, dc := range cfg {
+		l := &domainLimit{name: name, workers: dc.Workers}
+		if dc.Rate > 0 {
+			l.rate = newAdaptiveRate(dc.Rate, ac)
+			fmt.Printf("Sending to %s every %s via %d workers\n", name, l.rate.interval(), l.workers)
+		} else {
+			fmt.Printf("Sending to %s via %d workers\n", name, l.workers)
+		}
+
+		match := dc.Match
+		if len(match) == 0 {
+			match = []string{name}
+		}
+		for _, m := range match {
+			out[strings.ToLower(m)] = l
+		}
+	}
+	return out
+}
+
+// match finds limits for the email's domain or its parent domains
+func (dl domainLimits) match(email string) *domainLimit {
+	if len(dl) == 0 {
+		return nil
+	}
+
+	_, domain, _ := strings.Cut(strings.ToLower(email), "@")
+	for domain != "" {
+		if l, ok := dl[domain]; ok {
+			return l
+		}
+		_, domain, _ = strings.Cut(domain, ".")
+	}
+
+	return nil
+}
+
+// admit reserves a delivery slot for the task, or parks it until
+// one is available. Must be called with memoryQueue.schedL held.
+func (d *memoryQueue) admit(t *task, now time.Time) bool {
+	// Hold until eligible by schedule
+	if at := d.schedule.next(now, t.loc); at.After(now) {
+		t.readyAt, t.scheduled = at, true
+		d.delayed.push(t)
+		return false
+	}
+
+	// Wait for another delivery to this domain to finish
+	l := t.limit
+	if l != nil && l.workers > 0 && l.active >= l.workers {
+		l.waiting = append(l.waiting, t)
+		d.parked++
+		return false
+	}
+
+	// Delay until next reserved slot for this domain, or all
+	// domains for held tasks that skipped Enqueue throttling
+	rl := d.global
+	if l != nil && l.rate != nil {
+		rl = l
+	} else if !t.scheduled {
+		rl = nil
+	}
+
+	if rl != nil && !t.reserved {
+		interval := rl.rate.interval()
+		if rl.next.After(now) {
+			t.readyAt, t.reserved = rl.next, true
+			rl.next = rl.next.Add(interval)
+			d.delayed.push(t)
+			return false
+		}
+		rl.next = now.Add(interval)
+	}
+
+	t.reserved = false
+	if l != nil {
+		l.active++
+	}
+	return true
+}
+
+// release frees task's domain slot for the next waiting task.
+// Must be called with memoryQueue.schedL held.
+func (d *memoryQueue) release(t *task) {
+	l := t.limit
+	if l == nil {
+		return
+	}
+
+	l.active--
+	if len(l.waiting) > 0 {
+		next := l.waiting[0]
+		l.waiting = l.waiting[1:]
+		d.parked--
+
+		next.readyAt = time.Time{}
+		d.delayed.push(next)
+	}
+}
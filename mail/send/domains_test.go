@@ -0,0 +1,124 @@
+package send
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/wneessen/go-mail"
+)
+
+func TestDomainLimitsMatch(t *testing.T) {
+	limits := newDomainLimits(map[string]DomainConfig{
+		"gmail.com": {Rate: 5},
+		"microsoft": {Match: []string{"outlook.com", "Hotmail.com"}},
+	}, AdaptiveConfig{})
+
+	cases := []struct {
+		email string
+		limit string
+	}{
+		{"bob@gmail.com", "gmail.com"},
+		{"Bob@GMail.com", "gmail.com"},
+		{"bob@eu.gmail.com", "gmail.com"},
+		{"bob@outlook.com", "microsoft"},
+		{"bob@hotmail.com", "microsoft"},
+		{"bob@example.com", ""},
+		{"bob@com", ""},
+		{"invalid", ""},
+	}
+
+	for _, c := range cases {
+		name := ""
+		if l := limits.match(c.email); l != nil {
+			name = l.name
+		}
+		if name != c.limit {
+			t.Errorf("match(%q) = %q, expected %q", c.email, name, c.limit)
+		}
+	}
+}
+
+func TestMemoryQueue_DomainWorkers(t *testing.T) {
+	var active, peak atomic.Int32
+	sendFn := func(msg ...*mail.Msg) error {
+		if msg[0].GetTo()[0].Address != "bob@gmail.com" {
+			return nil
+		}
+		n := active.Add(1)
+		defer active.Add(-1)
+		for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+		}
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	}
+
+	cfg := &Config{
+		Workers: 3,
+		Domains: map[string]DomainConfig{"gmail.com": {Workers: 1}},
+	}
+	results := recordResults(cfg)
+	to := []string{"bob@gmail.com", "bob@gmail.com", "bob@gmail.com", "ann@example.com"}
+	if err := runQueue(t, cfg, sendFunc(sendFn), to); err != nil {
+		t.Fatalf("Wait() failed: %v", err)
+	}
+
+	if sent := countSent(results()); sent != 4 {
+		t.Errorf("Expected 4 sent messages, got %d", sent)
+	}
+	if p := peak.Load(); p != 1 {
+		t.Errorf("Expected 1 concurrent gmail.com delivery, got %d", p)
+	}
+}
+
+func TestMemoryQueue_DomainRate(t *testing.T) {
+	var mu sync.Mutex
+	var times []time.Time
+	sendFn := func(msg ...*mail.Msg) error {
+		if msg[0].GetTo()[0].Address == "bob@gmail.com" {
+			mu.Lock()
+			times = append(times, time.Now())
+			mu.Unlock()
+		}
+		return nil
+	}
+
+	cfg := &Config{
+		Workers:  3,
+		SendRate: 1, // Other domains only
+		Domains:  map[string]DomainConfig{"gmail.com": {Rate: 20}},
+	}
+	results := recordResults(cfg)
+
+	start := time.Now()
+	if err := runQueue(t, cfg, sendFunc(sendFn), []string{"bob@gmail.com", "bob@gmail.com", "bob@gmail.com"}); err != nil {
+		t.Fatalf("Wait() failed: %v", err)
+	}
+
+	if sent := countSent(results()); sent != 3 {
+		t.Errorf("Expected 3 sent messages, got %d", sent)
+	}
+	if elapsed := time.Since(start); elapsed > 900*time.Millisecond {
+		t.Errorf("Global rate should not apply to gmail.com, took %s", elapsed)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for i := 1; i < len(times); i++ {
+		if gap := times[i].Sub(times[i-1]); gap < 40*time.Millisecond {
+			t.Errorf("Expected ~50ms between gmail.com deliveries, got %s", gap)
+		}
+	}
+}
+
+// Number of messages sent among results
+func countSent(results []*Result) int {
+	n := 0
+	for _, r := range results {
+		if r.State == StateSent {
+			n++
+		}
+	}
+	return n
+}
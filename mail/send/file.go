@@ -0,0 +1,154 @@
+package send
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/wneessen/go-mail"
+)
+
+// Characters replaced in .eml file names
+var unsafeFileRx = regexp.MustCompile(`[^\w@.+-]+`)
+
+// Lines that need ">" quoting in mbox (mboxrd)
+var mboxFromRx = regexp.MustCompile(`(?m)^(>*From )`)
+
+// fileSender archives rendered messages instead of delivering them,
+// into a directory of .eml files, a Maildir, or an mbox file
+type fileSender struct {
+	fs    afero.Fs
+	path  string
+	write func(msg *mail.Msg, raw []byte) error
+
+	// Unique Maildir names
+	host    string
+	counter atomic.Int64
+
+	// Serialized mbox appends
+	lock sync.Mutex
+}
+
+func newFileSender(_ context.Context, cfg *SMTPConfig, u *url.URL) (Sender, error) {
+	s := &fileSender{fs: cfg.Fs, path: localPath(u)}
+	if s.path == "" {
+		return nil, fmt.Errorf("invalid %s URL: %s", u.Scheme, u)
+	}
+
+	// Relative to project, rather than working directory
+	if s.fs == nil || filepath.IsAbs(s.path) {
+		s.fs = afero.NewOsFs()
+	}
+
+	var err error
+	switch u.Scheme {
+	case "file":
+		s.write = s.writeEML
+		err = s.fs.MkdirAll(s.path, 0755)
+	case "maildir":
+		s.write = s.writeMaildir
+		s.host, _ = os.Hostname()
+		s.host = strings.NewReplacer("/", `\057`, ":", `\072`).Replace(s.host)
+		for _, sub := range []string{"tmp", "new", "cur"} {
+			err = errors.Join(err, s.fs.MkdirAll(filepath.Join(s.path, sub), 0755))
+		}
+	case "mbox":
+		s.write = s.writeMbox
+		err = s.fs.MkdirAll(filepath.Dir(s.path), 0755)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Local files have no connection state, so workers share the sender
+func (s *fileSender) NewConn() (Conn, error) {
+	return s, nil
+}
+
+func (s *fileSender) Send(msgs ...*mail.Msg) error {
+	return sendEach(msgs, func(msg *mail.Msg) error {
+		var raw bytes.Buffer
+		if _, err := msg.WriteTo(&raw); err != nil {
+			return &TransportError{Code: 554, Status: "5.6.0", Response: err.Error()}
+		}
+		return s.write(msg, raw.Bytes())
+	})
+}
+
+// writeEML names the file after recipient and Message-ID
+func (s *fileSender) writeEML(msg *mail.Msg, raw []byte) error {
+	name := strings.Trim(msg.GetMessageID(), "<>")
+	if to := msg.GetTo(); len(to) > 0 {
+		name = to[0].Address + "_" + name
+	}
+	name = unsafeFileRx.ReplaceAllString(name, "_") + ".eml"
+	return afero.WriteFile(s.fs, filepath.Join(s.path, name), raw, 0644)
+}
+
+// writeMaildir delivers to "tmp" first, then moves to "new"
+func (s *fileSender) writeMaildir(_ *mail.Msg, raw []byte) error {
+	now := time.Now()
+	name := fmt.Sprintf("%d.M%dP%dQ%d.%s", now.Unix(), now.Nanosecond()/1000,
+		os.Getpid(), s.counter.Add(1), s.host)
+
+	tmp := filepath.Join(s.path, "tmp", name)
+	if err := afero.WriteFile(s.fs, tmp, raw, 0644); err != nil {
+		return err
+	}
+	return s.fs.Rename(tmp, filepath.Join(s.path, "new", name))
+}
+
+// writeMbox appends message with a "From " separator line
+func (s *fileSender) writeMbox(msg *mail.Msg, raw []byte) error {
+	from, err := msg.GetSender(false)
+	if err != nil {
+		from = "MAILER-DAEMON"
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	f, err := s.fs.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	fmt.Fprintf(w, "From %s %s\n", strings.Trim(from, "<>"), time.Now().UTC().Format(time.ANSIC))
+	body := bytes.ReplaceAll(raw, []byte("\r\n"), []byte("\n"))
+	w.Write(mboxFromRx.ReplaceAll(body, []byte(">$1")))
+	if !bytes.HasSuffix(body, []byte("\n")) {
+		w.WriteString("\n")
+	}
+	w.WriteString("\n")
+
+	return errors.Join(w.Flush(), f.Close())
+}
+
+func (s *fileSender) Close() error {
+	return nil
+}
+
+// localPath from a transport URL, where "scheme:path" and
+// "scheme://path" are relative, and "scheme:///path" is absolute
+func localPath(u *url.URL) string {
+	if u.Opaque != "" {
+		return u.Opaque
+	}
+	return u.Host + u.Path
+}
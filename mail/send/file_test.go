@@ -0,0 +1,127 @@
+package send
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestFileSender_EML(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "out")
+	s := newTestTransport(t, "file://"+dir)
+
+	msg := newTestMsg(t)
+	if err := s.Send(msg); err != nil {
+		t.Fatalf("Send() failed: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if len(files) != 1 {
+		t.Fatalf("Expected 1 .eml file, got %v", files)
+	}
+	if name := filepath.Base(files[0]); !strings.HasPrefix(name, "bob@example.com_") {
+		t.Errorf("File should be named after recipient: %s", name)
+	}
+	if raw, _ := os.ReadFile(files[0]); !strings.Contains(string(raw), "Subject: Hello") {
+		t.Errorf("Unexpected file contents: %s", raw)
+	}
+}
+
+func TestFileSender_Maildir(t *testing.T) {
+	dir := t.TempDir()
+	s := newTestTransport(t, "maildir://"+dir)
+
+	if err := s.Send(newTestMsg(t), newTestMsg(t)); err != nil {
+		t.Fatalf("Send() failed: %v", err)
+	}
+
+	for sub, count := range map[string]int{"tmp": 0, "new": 2, "cur": 0} {
+		entries, err := os.ReadDir(filepath.Join(dir, sub))
+		if err != nil {
+			t.Fatalf("Missing Maildir %s: %v", sub, err)
+		} else if len(entries) != count {
+			t.Errorf("Expected %d messages in %s, got %d", count, sub, len(entries))
+		}
+	}
+}
+
+func TestFileSender_Mbox(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "archive.mbox")
+	s := newTestTransport(t, "mbox://"+path)
+
+	msg := newTestMsg(t)
+	msg.SetBodyString("text/plain", "Hi Bob\nFrom here on\n>From there\n")
+	if err := s.Send(msg, newTestMsg(t)); err != nil {
+		t.Fatalf("Send() failed: %v", err)
+	}
+
+	raw, _ := os.ReadFile(path)
+	mbox := string(raw)
+	if n := strings.Count(mbox, "\nFrom sender@example.com "); n != 1 || !strings.HasPrefix(mbox, "From sender@example.com ") {
+		t.Errorf("Expected 2 separated messages:\n%s", mbox)
+	}
+	if !strings.Contains(mbox, "\n>From here on\n>>From there\n") {
+		t.Errorf("Body should be quoted:\n%s", mbox)
+	}
+	if strings.Contains(mbox, "\r\n") {
+		t.Errorf("Should use local line endings:\n%s", mbox)
+	}
+}
+
+func TestFileSender_ProjectFs(t *testing.T) {
+	fs, abs := afero.NewMemMapFs(), t.TempDir()
+	for _, url := range []string{"file://out", "file://" + abs} {
+		s, err := NewSender(t.Context(), &SMTPConfig{URL: url, Fs: fs})
+		if err != nil {
+			t.Fatalf("NewSender(%q) failed: %v", url, err)
+		} else if err := s.(Conn).Send(newTestMsg(t)); err != nil {
+			t.Fatalf("Send() failed: %v", err)
+		}
+	}
+
+	// Relative in project, and absolute on disk
+	if files, _ := afero.ReadDir(fs, "out"); len(files) != 1 {
+		t.Errorf("Expected 1 email in project, got %d", len(files))
+	}
+	if files, _ := os.ReadDir(abs); len(files) != 1 {
+		t.Errorf("Expected 1 email in %s, got %d", abs, len(files))
+	}
+}
+
+func TestLocalPath(t *testing.T) {
+	cases := map[string]string{
+		"file:///var/mail": "/var/mail",
+		"file://out/mail":  "out/mail",
+		"file:out":         "out",
+		"maildir://":       "",
+	}
+
+	for in, expected := range cases {
+		u, _ := url.Parse(in)
+		if p := localPath(u); p != expected {
+			t.Errorf("Path for %q is %q, expected %q", in, p, expected)
+		}
+	}
+
+	if _, err := NewSender(t.Context(), &SMTPConfig{URL: "maildir://"}); err == nil {
+		t.Error("NewSender() should fail without a path")
+	}
+}
+
+func newTestTransport(t *testing.T, url string) Conn {
+	t.Helper()
+	s, err := NewSender(t.Context(), &SMTPConfig{URL: url})
+	if err != nil {
+		t.Fatalf("NewSender(%q) failed: %v", url, err)
+	}
+	c, err := s.NewConn()
+	if err != nil {
+		t.Fatalf("NewConn() failed: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
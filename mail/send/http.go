@@ -0,0 +1,208 @@
+package send
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/wneessen/go-mail"
+)
+
+// Timeout of a single HTTP API request
+const httpSendTimeout = 30 * time.Second
+
+// Maximum length of response body kept in errors
+const maxHTTPResponse = 512
+
+// JSON envelope posted by "http+json" and "https+json" transports
+type httpEnvelope struct {
+	From      string   `json:"from"`
+	To        []string `json:"to"`
+	MessageID string   `json:"messageId"`
+	Subject   string   `json:"subject"`
+	Message   string   `json:"message"` // RFC 5322
+}
+
+// httpSender posts messages to an ESP's HTTP API, either as
+// raw RFC 5322 (message/rfc822) or wrapped in a JSON envelope
+type httpSender struct {
+	context  context.Context
+	client   *http.Client
+	endpoint string
+	json     bool
+
+	// Authentication
+	headers    map[string]string
+	user, pass string
+}
+
+func newHTTPSender(ctx context.Context, cfg *SMTPConfig, u *url.URL) (Sender, error) {
+	s := &httpSender{
+		context: ctx,
+		client:  &http.Client{Timeout: httpSendTimeout},
+		headers: cfg.Headers,
+		user:    cfg.User,
+		pass:    cfg.Pass,
+	}
+
+	// Authentication from URL, unless overridden
+	if auth := u.User; auth != nil {
+		pass, _ := auth.Password()
+		s.user = cmp.Or(s.user, auth.Username())
+		s.pass = cmp.Or(s.pass, pass)
+	}
+
+	// Strip transport from scheme
+	endpoint := *u
+	endpoint.User = nil
+	endpoint.Scheme, s.json = strings.CutSuffix(u.Scheme, "+json")
+	s.endpoint = endpoint.String()
+
+	if endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid HTTP URL: %s", s.endpoint)
+	}
+
+	// Custom TLS config
+	if cfg.TLS != nil {
+		tlsMinVersion, err := cfg.TLS.GetMinVersion()
+		if err != nil {
+			return nil, err
+		}
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.TLSClientConfig = &tls.Config{
+			InsecureSkipVerify: cfg.TLS.InsecureSkipVerify,
+			MinVersion:         tlsMinVersion,
+		}
+		s.client.Transport = t
+	}
+
+	return s, nil
+}
+
+// HTTP requests are independent, so workers share the sender
+func (s *httpSender) NewConn() (Conn, error) {
+	return s, nil
+}
+
+func (s *httpSender) Send(msgs ...*mail.Msg) error {
+	return sendEach(msgs, s.send)
+}
+
+func (s *httpSender) send(msg *mail.Msg) error {
+	body, contentType, err := s.encode(msg)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(s.context, http.MethodPost, s.endpoint, body)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range s.headers {
+		req.Header.Set(k, v)
+	}
+	if s.user != "" || s.pass != "" {
+		req.SetBasicAuth(s.user, s.pass)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err // Temporary network failure
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	text, _ := io.ReadAll(io.LimitReader(resp.Body, maxHTTPResponse))
+	return httpError(resp.StatusCode, strings.TrimSpace(string(text)))
+}
+
+// encode renders message for the request body
+func (s *httpSender) encode(msg *mail.Msg) (io.Reader, string, error) {
+	var raw bytes.Buffer
+	if _, err := msg.WriteTo(&raw); err != nil {
+		return nil, "", &TransportError{Code: 554, Status: "5.6.0", Response: err.Error()}
+	}
+
+	if !s.json {
+		if len(msg.GetBcc()) > 0 {
+			return nil, "", &TransportError{Code: 554, Status: "5.3.3", Response: errRawBcc.Error()}
+		}
+		return &raw, "message/rfc822", nil
+	}
+
+	from, err := msg.GetSender(false)
+	if err != nil {
+		return nil, "", &TransportError{Code: 553, Status: "5.1.7", Response: err.Error()}
+	}
+
+	env := httpEnvelope{
+		From:      strings.Trim(from, "<>"),
+		MessageID: msg.GetMessageID(),
+		Message:   raw.String(),
+	}
+	if subj := msg.GetGenHeader(mail.HeaderSubject); len(subj) > 0 {
+		env.Subject = subj[0]
+	}
+	for _, a := range slices.Concat(msg.GetTo(), msg.GetCc(), msg.GetBcc()) {
+		env.To = append(env.To, a.Address)
+	}
+	if len(env.To) == 0 {
+		return nil, "", &TransportError{Code: 554, Status: "5.1.3", Response: "no recipients"}
+	}
+
+	body, err := json.Marshal(env)
+	return bytes.NewReader(body), "application/json", err
+}
+
+// Raw message has no envelope, while Bcc is not in its headers
+var errRawBcc = errors.New("Bcc is not supported by raw HTTP transport (use \"+json\")")
+
+// SupportsBcc reports whether sender delivers to Bcc recipients,
+// which are only in the envelope of a message
+func SupportsBcc(s Sender) bool {
+	hs, ok := s.(*httpSender)
+	return !ok || hs.json
+}
+
+// httpError maps an HTTP API response to its SMTP equivalent
+func httpError(status int, body string) error {
+	e := &TransportError{Response: fmt.Sprintf("HTTP %d", status)}
+	if body != "" {
+		e.Response += ": " + body
+	}
+
+	switch {
+	case status == http.StatusTooManyRequests:
+		e.Code, e.Status = 421, "4.7.0" // Throttled
+	case status == http.StatusRequestTimeout, status >= 500:
+		e.Code, e.Status = 451, "4.3.0" // Server error
+	case status == http.StatusUnauthorized, status == http.StatusForbidden:
+		e.Code, e.Status = 535, "5.7.8" // Authentication
+	case status == http.StatusRequestEntityTooLarge:
+		e.Code, e.Status = 552, "5.3.4" // Message size
+	default:
+		e.Code, e.Status = 554, "5.6.0" // Rejected message
+	}
+
+	return e
+}
+
+func (s *httpSender) Close() error {
+	return nil
+}
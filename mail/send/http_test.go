@@ -0,0 +1,214 @@
+package send
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/wneessen/go-mail"
+)
+
+func TestNewSender(t *testing.T) {
+	cases := []struct {
+		url  string
+		kind string
+		err  string
+	}{
+		{"smtp://smtp.host", "*send.smtpSender", ""},
+		{"//smtp.host", "*send.smtpSender", ""},
+		{"https://api.host/send", "*send.httpSender", ""},
+		{"http+json://api.host/send", "*send.httpSender", ""},
+		{"https+json:///send", "", "invalid HTTP URL"},
+		{"ftp://files.host", "", `unknown delivery transport "ftp"`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.url, func(t *testing.T) {
+			s, err := NewSender(t.Context(), &SMTPConfig{URL: c.url})
+			if c.err != "" {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Fatalf("Expected error %q, got %v", c.err, err)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("NewSender() failed: %v", err)
+			}
+			if k := fmt.Sprintf("%T", s); k != c.kind {
+				t.Errorf("Expected %s, got %s", c.kind, k)
+			}
+		})
+	}
+}
+
+func TestHTTPSender_Raw(t *testing.T) {
+	var body, contentType, apiKey string
+	var user, pass string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		body, contentType = string(raw), r.Header.Get("Content-Type")
+		apiKey = r.Header.Get("X-Api-Key")
+		user, pass, _ = r.BasicAuth()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	s := newTestHTTPSender(t, &SMTPConfig{
+		URL:     strings.Replace(srv.URL, "http://", "http://api:secret@", 1),
+		Headers: map[string]string{"x-api-key": "key123"},
+	})
+
+	if err := s.Send(newTestMsg(t)); err != nil {
+		t.Fatalf("Send() failed: %v", err)
+	}
+	if contentType != "message/rfc822" {
+		t.Errorf("Unexpected content type: %s", contentType)
+	}
+	if apiKey != "key123" || user != "api" || pass != "secret" {
+		t.Errorf("Unexpected authentication: %q %q:%q", apiKey, user, pass)
+	}
+	if !strings.Contains(body, "Subject: Hello") {
+		t.Errorf("Body should be RFC 5322 message: %s", body)
+	}
+
+	// Bcc has no place in raw message
+	if SupportsBcc(s) {
+		t.Errorf("Raw HTTP sender should not support Bcc")
+	}
+	msg := newTestMsg(t)
+	msg.Bcc("carol@example.com")
+	var te *TransportError
+	if err := s.Send(msg); !errors.As(err, &te) || te.Code != 554 {
+		t.Errorf("Expected permanent error for Bcc, got %v", err)
+	}
+}
+
+func TestHTTPSender_JSON(t *testing.T) {
+	var env httpEnvelope
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&env)
+	}))
+	defer srv.Close()
+
+	s := newTestHTTPSender(t, &SMTPConfig{
+		URL: strings.Replace(srv.URL, "http://", "http+json://", 1),
+	})
+
+	msg := newTestMsg(t)
+	if err := s.Send(msg); err != nil {
+		t.Fatalf("Send() failed: %v", err)
+	}
+	if env.From != "sender@example.com" || len(env.To) != 1 || env.To[0] != "bob@example.com" {
+		t.Errorf("Unexpected envelope: %+v", env)
+	}
+	if env.Subject != "Hello" || env.MessageID != msg.GetMessageID() {
+		t.Errorf("Unexpected headers: %+v", env)
+	}
+	if !strings.Contains(env.Message, "Subject: Hello") {
+		t.Errorf("Envelope should contain RFC 5322 message: %s", env.Message)
+	}
+}
+
+func TestHTTPError(t *testing.T) {
+	cases := []struct {
+		status int
+		code   int
+		temp   bool
+	}{
+		{429, 421, true},
+		{500, 451, true},
+		{503, 451, true},
+		{408, 451, true},
+		{401, 535, false},
+		{413, 552, false},
+		{400, 554, false},
+		{422, 554, false},
+	}
+
+	for _, c := range cases {
+		err := httpError(c.status, "details")
+		var te *TransportError
+		if !errors.As(err, &te) || te.Code != c.code {
+			t.Errorf("HTTP %d: expected code %d, got %v", c.status, c.code, err)
+		}
+		if temp := isTemporary(err); temp != c.temp {
+			t.Errorf("HTTP %d: isTemporary = %t, expected %t", c.status, temp, c.temp)
+		}
+	}
+}
+
+func TestHTTPSender_QueueResults(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+		}
+	}))
+	defer srv.Close()
+
+	var mu sync.Mutex
+	var results []*Result
+	ctx := context.Background()
+	queue, err := NewInMemory(ctx, &Config{
+		Workers: 1,
+		Retry:   RetryConfig{MaxAttempts: 2, InitialInterval: 10 * time.Millisecond},
+		Reporters: []Reporter{ReporterFunc(func(r *Result) {
+			mu.Lock()
+			results = append(results, r)
+			mu.Unlock()
+		})},
+	}, newTestHTTPSender(t, &SMTPConfig{URL: srv.URL}))
+	if err != nil {
+		t.Fatalf("NewInMemory() failed: %v", err)
+	}
+
+	queue.Enqueue(ctx, newTestMsg(t))
+	queue.Close()
+	if err := queue.Wait(); err != nil {
+		t.Fatalf("Wait() failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	if r := results[0]; r.State != StateDeferred || r.Code != 421 || r.Status != "4.7.0" {
+		t.Errorf("Unexpected throttled result: %+v", r)
+	}
+	if r := results[1]; r.State != StateSent {
+		t.Errorf("Unexpected final result: %+v", r)
+	}
+}
+
+func newTestHTTPSender(t *testing.T, cfg *SMTPConfig) *httpSender {
+	t.Helper()
+	s, err := NewSender(t.Context(), cfg)
+	if err != nil {
+		t.Fatalf("NewSender() failed: %v", err)
+	}
+	return s.(*httpSender)
+}
+
+func newTestMsg(t *testing.T) *mail.Msg {
+	t.Helper()
+	msg := mail.NewMsg()
+	if err := msg.From("Sender <sender@example.com>"); err != nil {
+		t.Fatal(err)
+	}
+	if err := msg.To("Bob <bob@example.com>"); err != nil {
+		t.Fatal(err)
+	}
+	msg.Subject("Hello")
+	msg.SetMessageID()
+	msg.SetBodyString(mail.TypeTextPlain, "Hi Bob")
+	return msg
+}
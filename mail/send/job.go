@@ -0,0 +1,335 @@
+package send
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// States of a send job
+const (
+	JobRunning   = "running"
+	JobPaused    = "paused"
+	JobCancelled = "cancelled"
+	JobDone      = "done"
+	JobFailed    = "failed"
+)
+
+// ErrJobCancelled is returned by a queue whose job was cancelled
+var ErrJobCancelled = errors.New("send job was cancelled")
+
+// Finished jobs kept for listing
+const maxFinishedJobs = 100
+
+// Recent delivery errors kept in progress
+const maxJobErrors = 10
+
+// Outcomes buffered for each subscriber
+const jobEventBuffer = 100
+
+// Job controls a running delivery, which can be paused, resumed
+// or cancelled, while workers finish their in-flight messages
+type Job struct {
+	ID       string
+	Campaign string
+	List     string
+	Created  time.Time
+
+	state    string
+	progress JobProgress
+	changed  chan struct{} // Closed on state change
+	done     chan struct{} // Closed when finished
+	events   map[chan *Result]struct{}
+	lock     sync.Mutex
+}
+
+// JobProgress is a snapshot of job's deliveries
+type JobProgress struct {
+	Total   int // Recipients, if known
+	Queued  int
+	Sent    int
+	Failed  int // Bounced, or out of retries
+	Skipped int // Delivered by a previous run
+
+	// Recent delivery errors, and the job's outcome
+	Errors []string
+	Error  string
+
+	Started  time.Time
+	Finished time.Time
+}
+
+// Remaining deliveries of the job
+func (p JobProgress) Remaining() int {
+	return max(p.Total, p.Queued+p.Skipped) - p.Sent - p.Failed - p.Skipped
+}
+
+// ETA estimates time until remaining deliveries are finished,
+// based on the pace so far (false if unknown)
+func (p JobProgress) ETA(now time.Time) (time.Duration, bool) {
+	done, left := p.Sent+p.Failed, p.Remaining()
+	if p.Started.IsZero() || !p.Finished.IsZero() || done == 0 {
+		return 0, false
+	}
+	pace := now.Sub(p.Started) / time.Duration(done)
+	return pace * time.Duration(left), true
+}
+
+// NewJob creates a running job, and registers it for FindJob
+func NewJob(campaign, list string) *Job {
+	j := newJob()
+	j.ID, j.Campaign, j.List = newJobID(), campaign, list
+	jobs.add(j)
+	return j
+}
+
+// Unregistered job, for queues without one
+func newJob() *Job {
+	return &Job{
+		Created: time.Now(),
+		state:   JobRunning,
+		changed: make(chan struct{}),
+		done:    make(chan struct{}),
+		events:  map[chan *Result]struct{}{},
+	}
+}
+
+func newJobID() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// State of the job (see Job* constants)
+func (j *Job) State() string {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	return j.state
+}
+
+// Finished time of the job (zero while running)
+func (j *Job) Finished() time.Time {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	return j.progress.Finished
+}
+
+// Progress of the job's deliveries
+func (j *Job) Progress() JobProgress {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	p := j.progress
+	p.Errors = slices.Clone(p.Errors)
+	return p
+}
+
+// SetTotal sets the expected number of deliveries
+func (j *Job) SetTotal(n int) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	j.progress.Total = n
+}
+
+// Pause stops dispatching messages until resumed
+func (j *Job) Pause() error {
+	return j.transition(JobPaused, JobRunning)
+}
+
+// Resume continues a paused job
+func (j *Job) Resume() error {
+	return j.transition(JobRunning, JobPaused)
+}
+
+// Cancel drops messages that have not been dispatched yet
+func (j *Job) Cancel() error {
+	return j.transition(JobCancelled, JobRunning, JobPaused)
+}
+
+// Done finishes the job with the outcome of its send
+func (j *Job) Done(err error) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	if !j.progress.Finished.IsZero() {
+		return
+	} else if err != nil {
+		j.progress.Error = err.Error()
+	}
+	j.progress.Finished = time.Now()
+
+	// Cancelled job keeps its state
+	if j.state != JobCancelled {
+		j.state = JobDone
+		if err != nil {
+			j.state = JobFailed
+		}
+	}
+
+	close(j.changed)
+	j.changed = make(chan struct{})
+
+	// End subscriptions
+	close(j.done)
+	for ch := range j.events {
+		delete(j.events, ch)
+		close(ch)
+	}
+}
+
+// Subscribe streams every delivery outcome, including deferred
+// retries, until the job is finished or ctx is done. Outcomes
+// are dropped while a subscriber's buffer is full.
+func (j *Job) Subscribe(ctx context.Context) <-chan *Result {
+	ch := make(chan *Result, jobEventBuffer)
+
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	if !j.progress.Finished.IsZero() {
+		close(ch)
+		return ch
+	}
+	j.events[ch] = struct{}{}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-j.done:
+			return // Closed by Done
+		}
+		j.lock.Lock()
+		defer j.lock.Unlock()
+		if _, ok := j.events[ch]; ok {
+			delete(j.events, ch)
+			close(ch)
+		}
+	}()
+
+	return ch
+}
+
+// start records when the first delivery began
+func (j *Job) start() {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	if j.progress.Started.IsZero() {
+		j.progress.Started = time.Now()
+	}
+}
+
+// enqueued counts a message added to the queue, or
+// skipped since it was delivered in a previous run
+func (j *Job) enqueued(skipped bool) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	if skipped {
+		j.progress.Skipped++
+	} else {
+		j.progress.Queued++
+	}
+}
+
+// Report counts final delivery outcomes of the job's queue
+func (j *Job) Report(r *Result) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	for ch := range j.events {
+		select {
+		case ch <- r:
+		default:
+		}
+	}
+
+	if r.State == StateSent {
+		j.progress.Sent++
+	} else if r.Failed() {
+		j.progress.Failed++
+		j.progress.Errors = append(j.progress.Errors, r.Email+": "+r.Response)
+		if n := len(j.progress.Errors); n > maxJobErrors {
+			j.progress.Errors = j.progress.Errors[n-maxJobErrors:]
+		}
+	}
+}
+
+// transition changes state, if current state is one of from
+func (j *Job) transition(to string, from ...string) error {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	if !slices.Contains(from, j.state) {
+		return fmt.Errorf("send job %s is %s", j.ID, j.state)
+	}
+
+	j.state = to
+
+	// Notify watchers
+	close(j.changed)
+	j.changed = make(chan struct{})
+	return nil
+}
+
+// watch returns current state, and a channel closed on its change
+func (j *Job) watch() (string, <-chan struct{}) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	return j.state, j.changed
+}
+
+// WithJob attaches a job to the context of SendCampaign
+func WithJob(ctx context.Context, j *Job) context.Context {
+	return context.WithValue(ctx, ctxJobKey, j)
+}
+
+// Accessor for the send job from context
+func CurrentJob(ctx context.Context) (*Job, bool) {
+	j, ok := ctx.Value(ctxJobKey).(*Job)
+	return j, ok && j != nil
+}
+
+// FindJob looks up a job created by NewJob
+func FindJob(id string) (*Job, bool) {
+	jobs.lock.Lock()
+	defer jobs.lock.Unlock()
+	j, ok := jobs.byID[id]
+	return j, ok
+}
+
+// Jobs lists registered jobs, oldest first
+func Jobs() []*Job {
+	jobs.lock.Lock()
+	defer jobs.lock.Unlock()
+	return slices.Clone(jobs.list)
+}
+
+// Registry of jobs in this process
+var jobs = &jobRegistry{byID: map[string]*Job{}}
+
+type jobRegistry struct {
+	byID map[string]*Job
+	list []*Job
+	lock sync.Mutex
+}
+
+// add registers the job, and forgets the oldest finished jobs
+func (r *jobRegistry) add(j *Job) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	finished := 0
+	for i := len(r.list) - 1; i >= 0; i-- {
+		if f := r.list[i]; !f.Finished().IsZero() {
+			if finished++; finished >= maxFinishedJobs {
+				delete(r.byID, f.ID)
+				r.list = slices.Delete(r.list, i, i+1)
+			}
+		}
+	}
+
+	r.byID[j.ID] = j
+	r.list = append(r.list, j)
+}
@@ -0,0 +1,196 @@
+package send
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/wneessen/go-mail"
+)
+
+func TestJobTransitions(t *testing.T) {
+	j := NewJob("c1", "list")
+	if f, ok := FindJob(j.ID); !ok || f != j {
+		t.Fatalf("Job %s is not registered", j.ID)
+	} else if jobs := Jobs(); jobs[len(jobs)-1] != j {
+		t.Fatalf("Job %s is not listed last", j.ID)
+	}
+
+	steps := []struct {
+		action func() error
+		state  string
+		err    bool
+	}{
+		{j.Resume, JobRunning, true},
+		{j.Pause, JobPaused, false},
+		{j.Pause, JobPaused, true},
+		{j.Resume, JobRunning, false},
+		{j.Cancel, JobCancelled, false},
+		{j.Resume, JobCancelled, true},
+		{j.Cancel, JobCancelled, true},
+	}
+
+	for i, s := range steps {
+		if err := s.action(); (err != nil) != s.err {
+			t.Errorf("Step %d: unexpected error %v", i, err)
+		} else if a := j.State(); a != s.state {
+			t.Errorf("Step %d: expected %s, got %s", i, s.state, a)
+		}
+	}
+
+	// Cancelled job is not done
+	if j.Done(ErrJobCancelled); j.State() != JobCancelled || j.Finished().IsZero() {
+		t.Errorf("Expected finished cancelled job, got %s", j.State())
+	} else if e := j.Progress().Error; e != ErrJobCancelled.Error() {
+		t.Errorf("Unexpected job error %q", e)
+	}
+
+	// Outcome of a running job
+	for _, err := range []error{nil, errors.New("boom")} {
+		j := newJob()
+		if j.Done(err); err == nil && j.State() != JobDone {
+			t.Errorf("Expected done job, got %s", j.State())
+		} else if err != nil && j.State() != JobFailed {
+			t.Errorf("Expected failed job, got %s", j.State())
+		}
+	}
+}
+
+func TestJobProgress(t *testing.T) {
+	j := newJob()
+	j.SetTotal(20)
+	j.enqueued(true)
+	for range 4 {
+		j.enqueued(false)
+	}
+
+	j.Report(&Result{State: StateSent})
+	j.Report(&Result{State: StateDeferred})
+	for i := range maxJobErrors + 2 {
+		j.Report(&Result{State: StateBounced, Email: fmt.Sprintf("u%d@example.com", i), Response: "No such user"})
+	}
+
+	p := j.Progress()
+	if p.Queued != 4 || p.Skipped != 1 || p.Sent != 1 || p.Failed != maxJobErrors+2 {
+		t.Errorf("Unexpected progress %+v", p)
+	} else if r := p.Remaining(); r != 6 {
+		t.Errorf("Unexpected remaining %d", r)
+	} else if len(p.Errors) != maxJobErrors || p.Errors[0] != "u2@example.com: No such user" {
+		t.Errorf("Unexpected errors %q", p.Errors)
+	}
+
+	// Pace of 2 deliveries in a minute
+	p = JobProgress{Total: 10, Sent: 1, Failed: 1, Started: time.Unix(0, 0)}
+	if eta, ok := p.ETA(time.Unix(60, 0)); !ok || eta != 4*time.Minute {
+		t.Errorf("Unexpected ETA %s", eta)
+	} else if _, ok := (JobProgress{Total: 10}).ETA(time.Now()); ok {
+		t.Errorf("Expected unknown ETA without deliveries")
+	}
+}
+
+func TestMemoryQueue_PauseResume(t *testing.T) {
+	job := newJob()
+	sending := make(chan struct{})
+	proceed := make(chan struct{})
+	var sent atomic.Int32
+
+	sendFn := func(msgs ...*mail.Msg) error {
+		if sent.Add(int32(len(msgs))) == 1 {
+			close(sending)
+			<-proceed
+		}
+		return nil
+	}
+
+	queue := newTestQueue(t, &Config{Job: job, BatchSize: 1}, sendFunc(sendFn))
+	enqueueTo(t, queue, testRecipients(1)...)
+
+	// Pause with a message in-flight
+	<-sending
+	if err := job.Pause(); err != nil {
+		t.Fatalf("Pause() failed: %v", err)
+	}
+	close(proceed)
+
+	enqueueTo(t, queue, testRecipients(4)...)
+	time.Sleep(50 * time.Millisecond)
+	if n := sent.Load(); n != 1 {
+		t.Fatalf("Expected only in-flight email while paused, got %d", n)
+	}
+
+	if err := job.Resume(); err != nil {
+		t.Fatalf("Resume() failed: %v", err)
+	}
+
+	queue.Close()
+	if err := queue.Wait(); err != nil {
+		t.Fatalf("Wait() failed: %v", err)
+	} else if n := sent.Load(); n != 5 {
+		t.Errorf("Expected 5 emails, got %d", n)
+	} else if p := job.Progress(); p.Queued != 5 || p.Sent != 5 || p.Remaining() != 0 {
+		t.Errorf("Unexpected progress %+v", p)
+	}
+}
+
+func TestMemoryQueue_Cancel(t *testing.T) {
+	job := newJob()
+	var sent atomic.Int32
+	queue := newTestQueue(t, &Config{Job: job, BatchSize: 1}, sendFunc(func(msgs ...*mail.Msg) error {
+		sent.Add(int32(len(msgs)))
+		return nil
+	}))
+
+	job.Pause()
+	enqueueTo(t, queue, testRecipients(5)...)
+	if err := job.Cancel(); err != nil {
+		t.Fatalf("Cancel() failed: %v", err)
+	}
+
+	msg := mail.NewMsg()
+	msg.To("late@example.com")
+	if err := queue.Enqueue(context.Background(), msg); !errors.Is(err, ErrJobCancelled) {
+		t.Errorf("Expected cancelled Enqueue, got %v", err)
+	}
+
+	queue.Close()
+	if err := queue.Wait(); !errors.Is(err, ErrJobCancelled) {
+		t.Errorf("Expected cancelled Wait, got %v", err)
+	} else if n := sent.Load(); n != 0 {
+		t.Errorf("Expected no emails after cancel, got %d", n)
+	} else if r := job.Progress().Remaining(); r != 5 {
+		t.Errorf("Expected 5 remaining emails, got %d", r)
+	}
+}
+
+func TestJobSubscribe(t *testing.T) {
+	j := newJob()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := j.Subscribe(ctx)
+	events := j.Subscribe(context.Background())
+
+	j.Report(&Result{Email: "a@example.com", State: StateDeferred})
+	cancel()
+	for range cancelled {
+		// Drained until closed on cancellation
+	}
+
+	j.Report(&Result{Email: "a@example.com", State: StateSent})
+	j.Done(nil)
+
+	var states []string
+	for r := range events {
+		states = append(states, r.State)
+	}
+	if e := []string{StateDeferred, StateSent}; !slices.Equal(states, e) {
+		t.Errorf("Expected events %q, got %q", e, states)
+	}
+
+	// Finished job has no events
+	if _, ok := <-j.Subscribe(context.Background()); ok {
+		t.Errorf("Expected closed subscription")
+	}
+}
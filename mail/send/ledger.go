@@ -0,0 +1,183 @@
+package send
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// Column order for CSV ledger
+var ledgerHeader = []string{
+	"time", "campaign", "index", "email", "message_id", "state",
+	"code", "status", "response", "worker", "attempts", "relay",
+}
+
+// Ledger is an audit trail of every delivery attempt
+// appended to a JSONL (default) or CSV file
+type Ledger struct {
+	file  afero.File
+	write func(*Result) error
+	lock  sync.Mutex
+}
+
+// OpenLedger appends to path, formatted according to its extension
+func OpenLedger(fs afero.Fs, path string) (*Ledger, error) {
+	if err := fs.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	file, err := fs.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Ledger{file: file}
+	switch filepath.Ext(path) {
+	case ".csv":
+		err = l.initCSV()
+	case ".jsonl":
+		enc := json.NewEncoder(file)
+		l.write = func(r *Result) error { return enc.Encode(r) }
+	default:
+		err = fmt.Errorf("unsupported ledger format: %s", path)
+	}
+
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return l, nil
+}
+
+func (l *Ledger) initCSV() error {
+	w := csv.NewWriter(l.file)
+	l.write = func(r *Result) error {
+		w.Write([]string{
+			r.Time.Format(time.RFC3339),
+			r.Campaign,
+			strconv.Itoa(r.Index),
+			r.Email,
+			r.MessageID,
+			r.State,
+			strconv.Itoa(r.Code),
+			r.Status,
+			r.Response,
+			strconv.Itoa(r.Worker),
+			strconv.Itoa(r.Attempts),
+			r.Relay,
+		})
+		w.Flush()
+		return w.Error()
+	}
+
+	// Header for new files only
+	if fi, err := l.file.Stat(); err != nil {
+		return err
+	} else if fi.Size() > 0 {
+		return nil
+	}
+
+	w.Write(ledgerHeader)
+	w.Flush()
+	return w.Error()
+}
+
+// Report appends delivery outcome to the ledger
+func (l *Ledger) Report(r *Result) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if err := l.write(r); err != nil {
+		fmt.Printf("Could not write delivery ledger: %s\n", err)
+	}
+}
+
+func (l *Ledger) Close() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.file.Close()
+}
+
+// ReadLedger calls fn with every result recorded in the ledger at path
+func ReadLedger(fs afero.Fs, path string, fn func(*Result)) error {
+	file, err := fs.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	switch filepath.Ext(path) {
+	case ".csv":
+		return readLedgerCSV(file, fn)
+	case ".jsonl":
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(nil, 1<<20)
+		for scanner.Scan() {
+			var r Result
+			if err := json.Unmarshal(scanner.Bytes(), &r); err == nil {
+				fn(&r) // Skip partial writes from a crash
+			}
+		}
+		return scanner.Err()
+	}
+	return fmt.Errorf("unsupported ledger format: %s", path)
+}
+
+func readLedgerCSV(r io.Reader, fn func(*Result)) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	// Columns by name, in case of reordering
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	cols := map[string]int{}
+	for i, h := range header {
+		cols[h] = i
+	}
+
+	for {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		field := func(name string) string {
+			if i, ok := cols[name]; ok && i < len(rec) {
+				return rec[i]
+			}
+			return ""
+		}
+
+		res := &Result{
+			Campaign:  field("campaign"),
+			Email:     field("email"),
+			MessageID: field("message_id"),
+			State:     field("state"),
+			Status:    field("status"),
+			Response:  field("response"),
+			Relay:     field("relay"),
+		}
+		res.Time, _ = time.Parse(time.RFC3339, field("time"))
+		res.Index, _ = strconv.Atoi(field("index"))
+		res.Code, _ = strconv.Atoi(field("code"))
+		res.Worker, _ = strconv.Atoi(field("worker"))
+		res.Attempts, _ = strconv.Atoi(field("attempts"))
+		fn(res)
+	}
+}
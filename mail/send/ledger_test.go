@@ -0,0 +1,135 @@
+package send
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+)
+
+func testResults() []*Result {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	return []*Result{
+		{Time: now, Campaign: "c1", Index: 0, Email: "a@example.com", MessageID: "<1@host>",
+			State: StateSent, Code: 250, Response: "2.0.0 Ok: queued", Worker: 1, Attempts: 1},
+		{Time: now, Campaign: "c1", Index: 1, Email: "b@example.com", MessageID: "<2@host>",
+			State: StateFailed, Code: 550, Status: "5.1.1", Response: "550 5.1.1 No such user", Worker: 0, Attempts: 2, Relay: "backup"},
+	}
+}
+
+func TestLedgerJSONL(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	// Write results in two separate sessions
+	for i := 0; i < 2; i++ {
+		l, err := OpenLedger(fs, "logs/ledger.jsonl")
+		if err != nil {
+			t.Fatalf("OpenLedger() failed: %v", err)
+		}
+		l.Report(testResults()[i])
+		if err := l.Close(); err != nil {
+			t.Fatalf("Close() failed: %v", err)
+		}
+	}
+
+	raw, _ := afero.ReadFile(fs, "logs/ledger.jsonl")
+	lines := strings.Split(strings.TrimSpace(string(raw)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 ledger lines, got %d: %s", len(lines), raw)
+	}
+
+	var r Result
+	if err := json.Unmarshal([]byte(lines[1]), &r); err != nil {
+		t.Fatalf("Invalid JSON: %s", err)
+	}
+	if r.Email != "b@example.com" || r.Code != 550 || r.Status != "5.1.1" || r.Attempts != 2 || r.Relay != "backup" {
+		t.Errorf("Unexpected ledger record: %+v", r)
+	}
+	if !r.Failed() {
+		t.Error("Record should be failed")
+	}
+}
+
+func TestLedgerCSV(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	for i := 0; i < 2; i++ {
+		l, err := OpenLedger(fs, "ledger.csv")
+		if err != nil {
+			t.Fatalf("OpenLedger() failed: %v", err)
+		}
+		l.Report(testResults()[i])
+		l.Close()
+	}
+
+	raw, _ := afero.ReadFile(fs, "ledger.csv")
+	rows, err := csv.NewReader(strings.NewReader(string(raw))).ReadAll()
+	if err != nil {
+		t.Fatalf("Invalid CSV: %s", err)
+	}
+
+	// Header is only written once
+	if len(rows) != 3 {
+		t.Fatalf("Expected 3 CSV rows, got %d: %s", len(rows), raw)
+	}
+	if h := strings.Join(rows[0], ","); h != strings.Join(ledgerHeader, ",") {
+		t.Errorf("Unexpected header: %s", h)
+	}
+	expected := "2025-01-02T03:04:05Z,c1,0,a@example.com,<1@host>,sent,250,,2.0.0 Ok: queued,1,1,"
+	if r := strings.Join(rows[1], ","); r != expected {
+		t.Errorf("Unexpected row:\n%s\nexpected:\n%s", r, expected)
+	}
+}
+
+func TestReadLedger(t *testing.T) {
+	for _, path := range []string{"ledger.jsonl", "ledger.csv"} {
+		fs := afero.NewMemMapFs()
+		l, err := OpenLedger(fs, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range testResults() {
+			l.Report(r)
+		}
+		l.Close()
+
+		var results []*Result
+		if err := ReadLedger(fs, path, func(r *Result) { results = append(results, r) }); err != nil {
+			t.Fatalf("%s: ReadLedger() failed: %v", path, err)
+		}
+		if d := cmp.Diff(testResults(), results); d != "" {
+			t.Errorf("%s: unexpected results: %s", path, d)
+		}
+	}
+
+	if err := ReadLedger(afero.NewMemMapFs(), "missing.jsonl", func(*Result) {}); err == nil {
+		t.Errorf("Expected error for missing ledger")
+	}
+}
+
+func TestLedgerUnsupportedFormat(t *testing.T) {
+	_, err := OpenLedger(afero.NewMemMapFs(), "ledger.xml")
+	if err == nil || !strings.Contains(err.Error(), "unsupported ledger format") {
+		t.Errorf("Expected unsupported format error, got %v", err)
+	}
+}
+
+func TestDeliveryErrorMessage(t *testing.T) {
+	failed := []*Result{}
+	for i := 0; i < 7; i++ {
+		failed = append(failed, &Result{Email: "x@example.com", Response: "boom"})
+	}
+
+	err := &DeliveryError{Total: 10, Failed: failed}
+	msg := err.Error()
+	if !strings.HasPrefix(msg, "failed to deliver 7 of 10 emails; x@example.com: boom") {
+		t.Errorf("Unexpected message: %s", msg)
+	}
+	if !strings.HasSuffix(msg, "; and 2 more") {
+		t.Errorf("Unexpected message: %s", msg)
+	}
+}
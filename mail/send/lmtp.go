@@ -0,0 +1,162 @@
+package send
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/textproto"
+	"net/url"
+	"os"
+	"regexp"
+
+	"github.com/wneessen/go-mail"
+)
+
+// Enhanced status code at the start of a reply (RFC 3463)
+var enhancedStatusRx = regexp.MustCompile(`^([245]\.\d{1,3}\.\d{1,3})\s*`)
+
+// lmtpSender delivers to a local mail store over LMTP (RFC 2033),
+// listening on a unix socket (lmtp:///path) or TCP (lmtp://host:port)
+type lmtpSender struct {
+	context context.Context
+	network string
+	address string
+}
+
+func newLMTPSender(ctx context.Context, _ *SMTPConfig, u *url.URL) (Sender, error) {
+	s := &lmtpSender{context: ctx, network: "tcp", address: u.Host}
+	if u.Host == "" {
+		s.network, s.address = "unix", u.Path
+	}
+
+	if s.address == "" {
+		return nil, fmt.Errorf("invalid LMTP URL: %s", u)
+	}
+
+	return s, nil
+}
+
+func (s *lmtpSender) NewConn() (Conn, error) {
+	var dialer net.Dialer
+	nc, err := dialer.DialContext(s.context, s.network, s.address)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &lmtpConn{text: textproto.NewConn(nc)}
+	if _, _, err := c.text.ReadResponse(220); err != nil {
+		c.text.Close()
+		return nil, err
+	}
+
+	host, err := os.Hostname()
+	if err != nil {
+		host = "localhost"
+	}
+
+	if err := c.cmd(250, "LHLO %s", host); err != nil {
+		c.text.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// lmtpConn is a worker's LMTP session
+type lmtpConn struct {
+	text *textproto.Conn
+}
+
+func (c *lmtpConn) Send(msgs ...*mail.Msg) error {
+	return sendEach(msgs, c.send)
+}
+
+func (c *lmtpConn) send(msg *mail.Msg) error {
+	from, err := msg.GetSender(false)
+	if err != nil {
+		return &TransportError{Code: 553, Status: "5.1.7", Response: err.Error()}
+	}
+	rcpts, err := msg.GetRecipients()
+	if err != nil {
+		return &TransportError{Code: 554, Status: "5.1.3", Response: err.Error()}
+	}
+
+	if err := c.cmd(250, "MAIL FROM:%s", from); err != nil {
+		return c.reset(err)
+	}
+
+	// Only accepted recipients get a reply after DATA
+	var errs error
+	accepted := 0
+	for _, rcpt := range rcpts {
+		if err := c.cmd(25, "RCPT TO:%s", rcpt); err != nil {
+			errs = errors.Join(errs, replyError(err))
+		} else {
+			accepted++
+		}
+	}
+
+	if accepted == 0 {
+		return c.reset(errs)
+	} else if err := c.cmd(354, "DATA"); err != nil {
+		return c.reset(err)
+	}
+
+	w := c.text.DotWriter()
+	if _, err := msg.WriteTo(w); err != nil {
+		w.Close()
+		return err // Broken session
+	} else if err := w.Close(); err != nil {
+		return err
+	}
+
+	// Delivery status for each recipient
+	for range accepted {
+		if _, _, err := c.text.ReadResponse(25); err != nil {
+			errs = errors.Join(errs, replyError(err))
+		}
+	}
+
+	return errs
+}
+
+// cmd issues a command and checks its reply code
+func (c *lmtpConn) cmd(code int, format string, args ...any) error {
+	id, err := c.text.Cmd(format, args...)
+	if err != nil {
+		return err
+	}
+
+	c.text.StartResponse(id)
+	defer c.text.EndResponse(id)
+	_, _, err = c.text.ReadResponse(code)
+	return err
+}
+
+// reset aborts the transaction after a rejection
+func (c *lmtpConn) reset(err error) error {
+	if rerr := c.cmd(250, "RSET"); rerr != nil {
+		return errors.Join(replyError(err), rerr)
+	}
+	return replyError(err)
+}
+
+func (c *lmtpConn) Close() error {
+	c.cmd(221, "QUIT")
+	return c.text.Close()
+}
+
+// replyError translates a server reply to TransportError
+func replyError(err error) error {
+	var tpErr *textproto.Error
+	if !errors.As(err, &tpErr) {
+		return err
+	}
+
+	e := &TransportError{Code: tpErr.Code, Response: tpErr.Msg}
+	if m := enhancedStatusRx.FindStringSubmatch(tpErr.Msg); m != nil {
+		e.Status, e.Response = m[1], tpErr.Msg[len(m[0]):]
+	}
+	return e
+}
@@ -0,0 +1,141 @@
+package send
+
+import (
+	"errors"
+	"net"
+	"net/textproto"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLMTPSender(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "lmtp.sock")
+	delivered := startTestLMTP(t, socket)
+	conn := newTestTransport(t, "lmtp://"+socket)
+
+	// Accepted delivery
+	if err := conn.Send(newTestMsg(t)); err != nil {
+		t.Fatalf("Send() failed: %v", err)
+	}
+
+	// Rejected recipient, and failed delivery after DATA
+	cases := []struct {
+		to     string
+		code   int
+		status string
+		temp   bool
+	}{
+		{"bad@example.com", 550, "5.1.1", false},
+		{"full@example.com", 452, "4.2.2", true},
+	}
+
+	for _, c := range cases {
+		msg := newTestMsg(t)
+		msg.To(c.to)
+
+		var te *TransportError
+		err := conn.Send(msg)
+		if !errors.As(err, &te) || te.Code != c.code || te.Status != c.status {
+			t.Errorf("Unexpected error for %s: %v", c.to, err)
+		} else if isTemporary(err) != c.temp {
+			t.Errorf("isTemporary for %s should be %t", c.to, c.temp)
+		}
+	}
+
+	// Session is still usable
+	if err := conn.Send(newTestMsg(t)); err != nil {
+		t.Fatalf("Send() after errors failed: %v", err)
+	}
+
+	conn.Close()
+	if msgs := <-delivered; len(msgs) != 2 || !strings.Contains(msgs[0], "Subject: Hello") {
+		t.Errorf("Unexpected delivered messages: %v", msgs)
+	}
+}
+
+func TestNewLMTPSender(t *testing.T) {
+	cases := map[string]string{
+		"lmtp:///var/run/lmtp": "unix /var/run/lmtp",
+		"lmtp://localhost:24":  "tcp localhost:24",
+	}
+
+	for in, expected := range cases {
+		s, err := NewSender(t.Context(), &SMTPConfig{URL: in})
+		if err != nil {
+			t.Fatalf("NewSender(%q) failed: %v", in, err)
+		}
+		if l := s.(*lmtpSender); l.network+" "+l.address != expected {
+			t.Errorf("Unexpected address for %q: %s %s", in, l.network, l.address)
+		}
+	}
+}
+
+// Minimal LMTP server for a single session, which
+// reports delivered messages when session is closed
+func startTestLMTP(t *testing.T, socket string) <-chan []string {
+	t.Helper()
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	out := make(chan []string, 1)
+	go func() {
+		var delivered []string
+		defer func() { out <- delivered }()
+
+		nc, err := l.Accept()
+		if err != nil {
+			return
+		}
+		c := textproto.NewConn(nc)
+		defer c.Close()
+
+		var rcpts []string
+		c.PrintfLine("220 localhost LMTP ready")
+		for {
+			line, err := c.ReadLine()
+			if err != nil {
+				return
+			}
+
+			cmd, arg, _ := strings.Cut(line, " ")
+			switch cmd {
+			case "LHLO":
+				c.PrintfLine("250-localhost")
+				c.PrintfLine("250 PIPELINING")
+			case "MAIL", "RSET":
+				rcpts = nil
+				c.PrintfLine("250 2.1.0 Ok")
+			case "RCPT":
+				if strings.Contains(arg, "bad@") {
+					c.PrintfLine("550 5.1.1 No such user")
+				} else {
+					rcpts = append(rcpts, arg)
+					c.PrintfLine("250 2.1.5 Ok")
+				}
+			case "DATA":
+				c.PrintfLine("354 Go ahead")
+				data, _ := c.ReadDotBytes()
+				for _, r := range rcpts {
+					if strings.Contains(r, "full@") {
+						c.PrintfLine("452 4.2.2 Mailbox full")
+					} else {
+						delivered = append(delivered, string(data))
+						c.PrintfLine("250 2.0.0 Delivered")
+					}
+				}
+				rcpts = nil
+			case "QUIT":
+				c.PrintfLine("221 Bye")
+				return
+			default:
+				c.PrintfLine("500 Unknown command")
+			}
+		}
+	}()
+
+	return out
+}
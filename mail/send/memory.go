@@ -2,7 +2,9 @@ package send
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
@@ -11,7 +13,8 @@ import (
 
 // memoryQueue is the built-in channel-based queue implementation
 type memoryQueue struct {
-	tasks   chan *mail.Msg
+	tasks   chan *task   // Enqueued
+	work    chan []*task // Batches dispatched to workers
 	waiter  *sync.WaitGroup
 	context context.Context
 	sender  Sender
@@ -22,9 +25,32 @@ type memoryQueue struct {
 	// ID for logging
 	ID string
 
+	// Paused or cancelled by user
+	job *Job
+
 	// Rate limiting
-	throttle time.Duration
-	workers  int
+	rate    *adaptiveRate
+	workers int
+
+	// Messages per Send call and per connection
+	batchSize    int
+	sessionLimit int
+
+	// Delayed tasks (e.g. retries) and tasks with workers
+	retry    RetryConfig
+	schedule Schedule
+	limits   domainLimits
+	global   *domainLimit // Rate of held tasks
+	delayed  taskHeap
+	parked   int // Waiting for a domain worker
+	inflight int
+	schedL   sync.Mutex
+	wake     chan struct{}
+
+	// Delivery outcomes
+	reporters []Reporter
+	stats     deliveryStats
+	statsL    sync.Mutex
 }
 
 // Delivery configuration
@@ -32,34 +58,96 @@ type Config struct {
 	QueueID  string
 	SendRate float32
 	Workers  int
+
+	// Retries of temporary failures
+	Retry RetryConfig
+
+	// Rate and worker limits by recipient domain
+	Domains map[string]DomainConfig
+
+	// Slow down when remote server is throttling
+	Adaptive AdaptiveConfig
+
+	// Messages per Send call, and per connection before
+	// reconnecting (zero for unlimited)
+	BatchSize    int
+	SessionLimit int
+
+	// Hold messages until scheduled time and window
+	Schedule Schedule
+
+	// Delivery state for NewOnDisk
+	StateFile string
+	Resume    bool
+
+	// Receive every delivery outcome (e.g. Ledger)
+	Reporters []Reporter
+
+	// Pause, resume or cancel delivery (optional)
+	Job *Job
 }
 
 // NewInMemory creates a new in-memory channel-based queue
 func NewInMemory(ctx context.Context, cfg *Config, sender Sender) (Manager, error) {
-	// Rate configuration
-	throttle := time.Duration(0)
-	if cfg.SendRate > 0 {
-		throttle = time.Duration(1000 / cfg.SendRate)
-		throttle = throttle * time.Millisecond
+	q, err := newMemoryQueue(ctx, cfg, sender)
+	if err != nil {
+		return nil, err // Avoid typed nil
 	}
+	return q, nil
+}
+
+func newMemoryQueue(ctx context.Context, cfg *Config, sender Sender) (*memoryQueue, error) {
+	// Rate configuration
+	rate := newAdaptiveRate(cfg.SendRate, cfg.Adaptive)
 
 	workers := cfg.Workers
 	if workers == 0 {
 		workers = 1
 	}
 
+	// Batches never exceed a session
+	batchSize := max(cfg.BatchSize, 1)
+	if cfg.SessionLimit > 0 {
+		batchSize = min(batchSize, cfg.SessionLimit)
+	}
+
 	// Display configuration
-	fmt.Printf("Sending an email every %s via %d workers\n", throttle, workers)
+	fmt.Printf("Sending an email every %s via %d workers\n", rate.interval(), workers)
+	if at := cfg.Schedule.At; !at.IsZero() {
+		fmt.Printf("Holding emails until %s\n", at.Format(time.RFC1123))
+	}
+	if w := cfg.Schedule.Window; !w.IsZero() {
+		fmt.Printf("Sending emails between %s in recipient's timezone\n", w)
+	}
+
+	job := cfg.Job
+	if job == nil {
+		job = newJob()
+	}
 
 	queue := &memoryQueue{
-		ID:       cfg.QueueID,
-		tasks:    make(chan *mail.Msg, 10),
-		waiter:   &sync.WaitGroup{},
-		context:  ctx,
-		sender:   sender,
-		throttle: throttle,
-		workers:  workers,
+		ID:      cfg.QueueID,
+		job:     job,
+		tasks:   make(chan *task, 10),
+		work:    make(chan []*task),
+		wake:    make(chan struct{}, 1),
+		waiter:  &sync.WaitGroup{},
+		context: ctx,
+		sender:  sender,
+		rate:    rate,
+		workers: workers,
+		retry:   cfg.Retry,
+		limits:  newDomainLimits(cfg.Domains, cfg.Adaptive),
+
+		schedule: cfg.Schedule,
+		global:   &domainLimit{name: "all domains", rate: rate},
+
+		batchSize:    batchSize,
+		sessionLimit: cfg.SessionLimit,
+
+		reporters: append(slices.Clone(cfg.Reporters), job),
 	}
+	job.start()
 
 	// Capture context cancellation for graceful exit
 	done := ctx.Done()
@@ -68,6 +156,9 @@ func NewInMemory(ctx context.Context, cfg *Config, sender Sender) (Manager, erro
 		queue.shutdown()
 	}()
 
+	// Feed workers with enqueued and delayed tasks
+	go queue.dispatch()
+
 	// Start delivery workers
 	for i := 0; i < workers; i++ {
 		if err := queue.startWorker(i); err != nil {
@@ -96,16 +187,26 @@ func (d *memoryQueue) Enqueue(ctx context.Context, msg *mail.Msg) error {
 
 	if stopped {
 		return fmt.Errorf("queue is closed")
+	} else if d.job.State() == JobCancelled {
+		return ErrJobCancelled
 	}
 
-	// Apply rate limiting
-	if d.throttle > 0 {
-		time.Sleep(d.throttle)
+	// Apply rate limiting, unless the domain has its own
+	// or task is held for schedule (see memoryQueue.admit)
+	t := newTask(ctx, msg)
+	t.limit = d.limits.match(t.recipient())
+	if now := time.Now(); d.schedule.next(now, t.loc).After(now) {
+		t.scheduled = true
+	} else if t.limit == nil || t.limit.rate == nil {
+		if throttle := d.rate.interval(); throttle > 0 {
+			time.Sleep(throttle)
+		}
 	}
 
 	// Send to channel (non-blocking check for closure)
 	select {
-	case d.tasks <- msg:
+	case d.tasks <- t:
+		d.job.enqueued(false)
 		return nil
 	case <-ctx.Done():
 		return ctx.Err()
@@ -114,16 +215,28 @@ func (d *memoryQueue) Enqueue(ctx context.Context, msg *mail.Msg) error {
 		if stopped {
 			return fmt.Errorf("queue is closed")
 		}
-		// Try again with blocking send
-		d.tasks <- msg
-		return nil
+		// Try again with blocking send (e.g. while paused)
+		select {
+		case d.tasks <- t:
+			d.job.enqueued(false)
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
 	}
 }
 
 // Wait blocks until all queued tasks are completed
+// Returns a DeliveryError summary if any deliveries failed
 func (d *memoryQueue) Wait() error {
 	d.waiter.Wait()
-	return nil
+
+	d.statsL.Lock()
+	defer d.statsL.Unlock()
+	if d.job.State() == JobCancelled {
+		return errors.Join(ErrJobCancelled, d.stats.err())
+	}
+	return d.stats.err()
 }
 
 // Close gracefully shuts down the queue
@@ -143,10 +256,165 @@ func (d *memoryQueue) shutdown() {
 	}
 }
 
+// Maximum of delayed and parked tasks before dispatcher
+// stops accepting new ones, to apply backpressure on Enqueue
+const maxPending = 1000
+
+// dispatch hands batches of enqueued and ready delayed tasks
+// to workers until the queue is closed and every task is finalized.
+// A paused job holds on to its tasks, and a cancelled one drops them.
+func (d *memoryQueue) dispatch() {
+	defer close(d.work)
+	tasks := d.tasks
+	var batch []*task
+
+	for {
+		state, changed := d.job.watch()
+		if state == JobCancelled {
+			batch = d.drop(batch)
+		}
+
+		// Fill the batch with ready delayed tasks
+		d.schedL.Lock()
+		now, wait := time.Now(), time.Duration(0)
+		for state == JobRunning && len(batch) < d.batchSize {
+			t, w := d.delayed.popReady(now)
+			if t == nil {
+				wait = w
+				break
+			} else if d.admit(t, now) {
+				batch = append(batch, t)
+				d.inflight++
+			}
+		}
+		pending := d.delayed.Len() + d.parked
+		done := len(batch) == 0 && pending == 0 && tasks == nil && d.inflight == 0
+		d.schedL.Unlock()
+
+		if done {
+			return
+		}
+
+		// Hand over the batch once a worker is available
+		var work chan []*task
+		if len(batch) > 0 && state == JobRunning {
+			work = d.work
+		}
+
+		// Meanwhile, keep filling it with new tasks
+		input := tasks
+		if pending >= maxPending || len(batch) >= d.batchSize || state == JobPaused {
+			input = nil
+		}
+
+		// Or wait for next delayed task
+		var timer *time.Timer
+		var ready <-chan time.Time
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			ready = timer.C
+		}
+
+		select {
+		case work <- batch:
+			batch = nil
+		case t, more := <-input:
+			if !more {
+				tasks = nil // Closed, only delayed tasks remain
+				break
+			} else if state == JobCancelled {
+				break // Dropped
+			}
+			d.schedL.Lock()
+			if d.admit(t, time.Now()) {
+				batch = append(batch, t)
+				d.inflight++
+			}
+			d.schedL.Unlock()
+		case <-ready:
+		case <-changed:
+		case <-d.wake:
+		case <-d.context.Done():
+			return
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// drop discards undispatched tasks of a cancelled job
+func (d *memoryQueue) drop(batch []*task) []*task {
+	d.schedL.Lock()
+	defer d.schedL.Unlock()
+
+	dropped := len(batch) + d.delayed.Len() + d.parked
+	for _, t := range batch {
+		d.inflight--
+		if t.limit != nil {
+			t.limit.active--
+		}
+	}
+	for _, l := range d.limits {
+		l.waiting = nil
+	}
+	d.delayed, d.parked = nil, 0
+
+	if dropped > 0 {
+		fmt.Printf("Cancelled %s, dropped %d emails\n", d.ID, dropped)
+	}
+	return nil
+}
+
+// finish finalizes the task, or delays it for a retry
+func (d *memoryQueue) finish(t *task, retryIn time.Duration) {
+	d.schedL.Lock()
+	d.inflight--
+	d.release(t)
+	if retryIn > 0 {
+		t.readyAt = time.Now().Add(retryIn)
+		d.delayed.push(t)
+	}
+	d.schedL.Unlock()
+
+	// Non-blocking dispatcher wakeup
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// adapt slows down the task's send rate on remote throttling,
+// and speeds it back up as deliveries succeed
+func (d *memoryQueue) adapt(id int, t *task, r *Result) {
+	rate, name := d.rate, "all domains"
+	if t.limit != nil && t.limit.rate != nil {
+		rate, name = t.limit.rate, t.limit.name
+	}
+
+	if r.State == StateSent {
+		rate.succeeded()
+	} else if isThrottled(r) {
+		interval := rate.throttled()
+		fmt.Printf("[%d] Throttled by server, sending to %s every %s\n", id, name, interval)
+	}
+}
+
+// report records delivery outcome and notifies reporters
+func (d *memoryQueue) report(r *Result) {
+	d.statsL.Lock()
+	d.stats.add(r)
+	d.statsL.Unlock()
+
+	for _, rep := range d.reporters {
+		rep.Report(r)
+	}
+}
+
 // startWorker spawns a worker goroutine to process tasks
 func (d *memoryQueue) startWorker(id int) error {
 	fmt.Printf("[%d] Starting worker...\n", id)
-	d.waiter.Add(1)
 
 	// Dial up the sender
 	conn, err := d.sender.NewConn()
@@ -154,34 +422,51 @@ func (d *memoryQueue) startWorker(id int) error {
 		return err
 	}
 
+	d.waiter.Add(1)
 	go func() {
 		defer d.waiter.Done()
 		defer fmt.Printf("[%d] Stopping worker...\n", id)
-		defer conn.Close()
+		defer func() {
+			if conn != nil {
+				conn.Close()
+			}
+		}()
+
+		// Messages sent in this session
+		sent := 0
 
 		for {
 			select {
 			case <-d.context.Done():
 				fmt.Printf("[%d] Worker stopped on cancellation\n", id)
 				return
-			case msg, more := <-d.tasks:
+			case batch, more := <-d.work:
 				if !more {
 					return
 				}
 
-				// Log the sending
-				toList := msg.GetToString()
-				fmt.Printf("[%d] Sending %s to %s\n", id, d.ID, toList)
-
-				// Send the message
-				if err := conn.Send(msg); err != nil {
-					fmt.Printf("[%d] Could not send email: %s\n", id, err)
-					conn.Close() // Replace errored connection
-					conn, err = d.sender.NewConn()
-					if err != nil {
-						fmt.Printf("[%d] Failed to recreate sender after error: %v\n", id, err)
+				for len(batch) > 0 {
+					// Split the batch at session limit
+					n := len(batch)
+					if d.sessionLimit > 0 {
+						n = min(n, d.sessionLimit-sent)
+					}
+
+					broken := d.deliver(id, conn, batch[:n])
+					batch, sent = batch[n:], sent+n
+					if !broken && (d.sessionLimit == 0 || sent < d.sessionLimit) {
+						continue
+					} else if !broken {
+						fmt.Printf("[%d] Starting new session after %d emails\n", id, sent)
+					}
+
+					// Replace errored or exhausted connection
+					conn.Close()
+					if conn, err = d.reconnect(id); err != nil {
+						d.abandon(id, batch, err)
 						return
 					}
+					sent = 0
 				}
 			}
 		}
@@ -189,3 +474,97 @@ func (d *memoryQueue) startWorker(id int) error {
 
 	return nil
 }
+
+// reconnect replaces a connection, retrying failed attempts with
+// backoff of the retry policy, until the queue or its job is cancelled
+func (d *memoryQueue) reconnect(id int) (Conn, error) {
+	b := d.retry.newBackOff()
+	for {
+		conn, err := d.sender.NewConn()
+		if err == nil {
+			return conn, nil
+		}
+
+		wait := b.NextBackOff()
+		fmt.Printf("[%d] Failed to recreate sender, retrying in %s: %v\n", id, wait.Round(time.Millisecond), err)
+		if !d.sleep(wait) {
+			return nil, err
+		}
+	}
+}
+
+// sleep waits for duration, unless the queue or its job is cancelled
+func (d *memoryQueue) sleep(duration time.Duration) bool {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	for {
+		state, changed := d.job.watch()
+		if state == JobCancelled {
+			return false
+		}
+
+		select {
+		case <-d.context.Done():
+			return false
+		case <-timer.C:
+			return true
+		case <-changed:
+		}
+	}
+}
+
+// deliver sends a batch of tasks in one call, and reports each
+// outcome. Returns true if the connection should be replaced.
+func (d *memoryQueue) deliver(id int, conn Conn, batch []*task) bool {
+	msgs := make([]*mail.Msg, len(batch))
+	for i, t := range batch {
+		fmt.Printf("[%d] Sending %s to %s\n", id, d.ID, t.msg.GetToString())
+		t.msg.SetDate() // Held, or retried, since rendered
+		msgs[i] = t.msg
+		t.attempts++
+	}
+
+	err := conn.Send(msgs...)
+
+	broken := false
+	for i, t := range batch {
+		r := d.complete(id, conn, t, messageError(err, msgs, i))
+		broken = broken || (r.State != StateSent && r.State != StateBounced)
+	}
+
+	if broken {
+		fmt.Printf("[%d] Could not send email: %s\n", id, err)
+	}
+
+	return broken
+}
+
+// abandon fails remaining tasks of a worker that lost its connection
+func (d *memoryQueue) abandon(id int, batch []*task, err error) {
+	for _, t := range batch {
+		t.attempts++
+		d.complete(id, nil, t, err)
+	}
+}
+
+// complete reports the task's outcome, and retries temporary failures
+func (d *memoryQueue) complete(id int, conn Conn, t *task, err error) *Result {
+	r := newResult(d.ID, id, t, err)
+	if rc, ok := conn.(RelayConn); ok {
+		r.Relay = rc.Relay()
+	}
+
+	// Temporary failures are retried with backoff, unless cancelled
+	var retryIn time.Duration
+	if r.State == StateFailed && d.job.State() != JobCancelled {
+		if retryIn = d.retry.next(t); retryIn > 0 {
+			r.State = StateDeferred
+		}
+	}
+
+	d.report(r)
+	d.finish(t, retryIn)
+	d.adapt(id, t, r)
+	return r
+}
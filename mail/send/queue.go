@@ -23,7 +23,7 @@ type Manager interface {
 }
 
 // Sender interface for creating per-worker connections
-// Implemented by mail.smtpSender and mail.testSender
+// Implemented by transports (see RegisterTransport) and testSender
 type Sender interface {
 	NewConn() (Conn, error)
 }
@@ -3,11 +3,15 @@ package send
 import (
 	"context"
 	"errors"
+	"fmt"
+	netmail "net/mail"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/spf13/afero"
 	"github.com/wneessen/go-mail"
 )
 
@@ -61,6 +65,81 @@ func (m *mockConn) getSent() []*mail.Msg {
 	return append([]*mail.Msg{}, m.sent...)
 }
 
+// sendFunc is a sender, whose every connection sends with fn
+func sendFunc(fn func(...*mail.Msg) error) *mockSender {
+	return &mockSender{connFunc: func() (Conn, error) {
+		return &mockConn{sendFunc: fn}, nil
+	}}
+}
+
+// newTestQueue starts a queue with cfg and sender,
+// which is on disk of fs, if given, and in memory otherwise
+func newTestQueue(t *testing.T, cfg *Config, sender Sender, fs ...afero.Fs) Manager {
+	t.Helper()
+	var queue Manager
+	var err error
+	if len(fs) > 0 {
+		queue, err = NewOnDisk(context.Background(), cfg, sender, fs[0])
+	} else {
+		queue, err = NewInMemory(context.Background(), cfg, sender)
+	}
+	if err != nil {
+		t.Fatalf("Failed to start queue: %v", err)
+	}
+	return queue
+}
+
+// enqueueTo adds a message for each recipient, in list order
+func enqueueTo(t *testing.T, queue Manager, to ...string) {
+	t.Helper()
+	for i, addr := range to {
+		msg := mail.NewMsg()
+		msg.From("sender@example.com")
+		if err := msg.To(addr); err != nil {
+			t.Fatalf("Failed to set To: %v", err)
+		}
+		if err := queue.Enqueue(WithRecipientIndex(context.Background(), i), msg); err != nil {
+			t.Fatalf("Enqueue() failed: %v", err)
+		}
+	}
+}
+
+// runQueue delivers a message to each recipient through a queue
+// (see newTestQueue), and returns the error of Wait
+func runQueue(t *testing.T, cfg *Config, sender Sender, to []string, fs ...afero.Fs) error {
+	t.Helper()
+	queue := newTestQueue(t, cfg, sender, fs...)
+	enqueueTo(t, queue, to...)
+	queue.Close()
+	return queue.Wait()
+}
+
+// recordResults adds a reporter to cfg, and returns a snapshot of its results
+func recordResults(cfg *Config) func() []*Result {
+	var mu sync.Mutex
+	var results []*Result
+	cfg.Reporters = append(cfg.Reporters, ReporterFunc(func(r *Result) {
+		mu.Lock()
+		results = append(results, r)
+		mu.Unlock()
+	}))
+
+	return func() []*Result {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(results)
+	}
+}
+
+// testRecipients are n addresses of user<i>@example.com
+func testRecipients(n int) []string {
+	out := make([]string, n)
+	for i := range out {
+		out[i] = fmt.Sprintf("user%d@example.com", i)
+	}
+	return out
+}
+
 func TestNewInMemory_Success(t *testing.T) {
 	ctx := context.Background()
 	cfg := &Config{
@@ -542,3 +621,93 @@ func TestMemoryQueue_ConcurrentEnqueue(t *testing.T) {
 		t.Errorf("Expected %d messages sent, got %d", expectedCount, sendCount.Load())
 	}
 }
+
+func TestMemoryQueue_WaitReportsFailures(t *testing.T) {
+	ctx := context.Background()
+
+	var reported []*Result
+	var mu sync.Mutex
+	cfg := &Config{
+		QueueID: "test-campaign",
+		Workers: 1,
+		Reporters: []Reporter{ReporterFunc(func(r *Result) {
+			mu.Lock()
+			reported = append(reported, r)
+			mu.Unlock()
+		})},
+	}
+
+	conn := &mockConn{
+		sendFunc: func(msg ...*mail.Msg) error {
+			if msg[0].GetTo()[0].Address == "bad@example.com" {
+				return errors.New("send failed")
+			}
+			return nil
+		},
+	}
+	sender := &mockSender{
+		connFunc: func() (Conn, error) { return conn, nil },
+	}
+
+	queue, err := NewInMemory(ctx, cfg, sender)
+	if err != nil {
+		t.Fatalf("NewInMemory() failed: %v", err)
+	}
+
+	for i, to := range []string{"good@example.com", "bad@example.com"} {
+		msg := mail.NewMsg()
+		msg.SetMessageIDWithValue("id@example.com")
+		if err := msg.To(to); err != nil {
+			t.Fatalf("Failed to set To: %v", err)
+		}
+		if err := queue.Enqueue(WithRecipientIndex(ctx, i), msg); err != nil {
+			t.Fatalf("Enqueue() failed: %v", err)
+		}
+	}
+
+	queue.Close()
+	err = queue.Wait()
+
+	var dErr *DeliveryError
+	if !errors.As(err, &dErr) {
+		t.Fatalf("Expected DeliveryError, got: %v", err)
+	}
+	if dErr.Total != 2 || len(dErr.Failed) != 1 {
+		t.Errorf("Unexpected summary: %+v", dErr)
+	}
+
+	if len(reported) != 2 {
+		t.Fatalf("Expected 2 reported results, got %d", len(reported))
+	}
+	if r := reported[1]; r.Index != 1 || r.Email != "bad@example.com" || r.State != StateFailed {
+		t.Errorf("Unexpected failure result: %+v", r)
+	}
+	if r := reported[0]; r.MessageID != "<id@example.com>" || r.Attempts != 1 || r.Worker != 0 {
+		t.Errorf("Unexpected success result: %+v", r)
+	}
+}
+
+func TestMemoryQueue_DateOnDelivery(t *testing.T) {
+	var date string
+	queue := newTestQueue(t, &Config{Workers: 1}, sendFunc(func(msgs ...*mail.Msg) error {
+		date = msgs[0].GetGenHeader(mail.HeaderDate)[0]
+		return nil
+	}))
+
+	// Rendered long before delivery
+	rendered := time.Now().Add(-3 * time.Hour)
+	msg := mail.NewMsg()
+	msg.To("user@example.com")
+	msg.SetDateWithValue(rendered)
+	if err := queue.Enqueue(context.Background(), msg); err != nil {
+		t.Fatalf("Enqueue() failed: %v", err)
+	}
+
+	queue.Close()
+	if err := queue.Wait(); err != nil {
+		t.Fatalf("Wait() failed: %v", err)
+	}
+	if d, err := netmail.ParseDate(date); err != nil || d.Before(rendered.Add(time.Hour)) {
+		t.Errorf("Expected Date of delivery, got %q", date)
+	}
+}
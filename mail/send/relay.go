@@ -0,0 +1,198 @@
+package send
+
+import (
+	"cmp"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/wneessen/go-mail"
+)
+
+// Named SMTP relay, preferred by priority (lowest first)
+// and shared by weight with relays of the same priority
+type RelayConfig struct {
+	Name     string
+	URL      string
+	User     string
+	Pass     string
+	TLS      *TLSConfig // Defaults to SMTPConfig.TLS
+	Priority int
+	Weight   int // Defaults to 1
+}
+
+// RelayConn is a connection that knows which relay it is using
+type RelayConn interface {
+	Conn
+	Relay() string
+}
+
+// relay is a configured relay and its health
+type relay struct {
+	name     string
+	config   SMTPConfig
+	priority int
+	weight   int
+
+	// Guarded by relayPool.lock
+	failures  int
+	downUntil time.Time
+}
+
+// relayPool chooses relays for new connections
+type relayPool struct {
+	relays      []*relay
+	maxFailures int
+	cooldown    time.Duration
+	lock        sync.Mutex
+}
+
+func newRelayPool(cfg *SMTPConfig) *relayPool {
+	p := &relayPool{maxFailures: cfg.MaxFailures, cooldown: cfg.Cooldown}
+
+	// Single relay from top-level config
+	if len(cfg.Relays) == 0 {
+		p.relays = []*relay{{config: *cfg, weight: 1}}
+		return p
+	}
+
+	for _, rc := range cfg.Relays {
+		r := &relay{
+			name:     rc.Name,
+			priority: rc.Priority,
+			weight:   max(rc.Weight, 1),
+			config: SMTPConfig{
+				URL:  rc.URL,
+				User: rc.User,
+				Pass: rc.Pass,
+				TLS:  cmp.Or(rc.TLS, cfg.TLS),
+			},
+		}
+		if r.name == "" {
+			r.name = rc.URL
+		}
+		p.relays = append(p.relays, r)
+	}
+
+	slices.SortStableFunc(p.relays, func(a, b *relay) int {
+		return cmp.Compare(a.priority, b.priority)
+	})
+
+	return p
+}
+
+// candidates orders relays to attempt, by priority and random
+// weighted choice, followed by failed relays as a last resort
+func (p *relayPool) candidates(now time.Time) []*relay {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	var healthy, down []*relay
+	for _, r := range p.relays {
+		if r.downUntil.After(now) {
+			down = append(down, r)
+		} else {
+			healthy = append(healthy, r)
+		}
+	}
+
+	// Shuffle each priority group by weight
+	out := make([]*relay, 0, len(p.relays))
+	for len(healthy) > 0 {
+		end := 1
+		for end < len(healthy) && healthy[end].priority == healthy[0].priority {
+			end++
+		}
+		out = append(out, weightedShuffle(healthy[:end])...)
+		healthy = healthy[end:]
+	}
+
+	// Soonest recovering relays first
+	slices.SortStableFunc(down, func(a, b *relay) int {
+		return a.downUntil.Compare(b.downUntil)
+	})
+
+	return append(out, down...)
+}
+
+// weightedShuffle orders relays by repeated weighted random choice
+func weightedShuffle(group []*relay) []*relay {
+	rest := slices.Clone(group)
+	out := make([]*relay, 0, len(rest))
+	for len(rest) > 0 {
+		total := 0
+		for _, r := range rest {
+			total += r.weight
+		}
+
+		i, n := 0, rand.IntN(total)
+		for n >= rest[i].weight {
+			n -= rest[i].weight
+			i++
+		}
+
+		out = append(out, rest[i])
+		rest = slices.Delete(rest, i, i+1)
+	}
+	return out
+}
+
+// failed counts a failure, and takes relay out of
+// rotation after too many consecutive ones
+func (p *relayPool) failed(r *relay) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	r.failures++
+	if r.failures < p.maxFailures {
+		return false
+	}
+
+	r.failures = 0
+	r.downUntil = time.Now().Add(p.cooldown)
+	return true
+}
+
+// succeeded resets relay's consecutive failures
+func (p *relayPool) succeeded(r *relay) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	r.failures = 0
+}
+
+// down takes relay out of rotation immediately (e.g. dial failure)
+func (p *relayPool) down(r *relay) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	r.failures = 0
+	r.downUntil = time.Now().Add(p.cooldown)
+}
+
+// relayConn tracks relay health with every delivery
+type relayConn struct {
+	*mail.Client
+	relay *relay
+	pool  *relayPool
+}
+
+func (c *relayConn) Relay() string {
+	return c.relay.name
+}
+
+func (c *relayConn) Send(msgs ...*mail.Msg) error {
+	err := c.Client.Send(msgs...)
+	if err == nil {
+		c.pool.succeeded(c.relay)
+	} else if isTemporary(err) && c.pool.failed(c.relay) {
+		logRelayDown(c.relay, err)
+	}
+	return err
+}
+
+func logRelayDown(r *relay, err error) {
+	if r.name != "" {
+		fmt.Printf("Failing over from relay %s on error: %s\n", r.name, err)
+	}
+}
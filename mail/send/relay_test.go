@@ -0,0 +1,196 @@
+package send
+
+import (
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRelayPoolDefault(t *testing.T) {
+	p := newRelayPool(&SMTPConfig{URL: "smtp://smtp.host"})
+	c := p.candidates(time.Now())
+	if len(c) != 1 || c[0].config.URL != "smtp://smtp.host" || c[0].name != "" {
+		t.Fatalf("Unexpected relays: %+v", c)
+	}
+}
+
+func TestRelayPoolCandidates(t *testing.T) {
+	tls := &TLSConfig{MinVersion: "1.3"}
+	p := newRelayPool(&SMTPConfig{
+		TLS:         tls,
+		MaxFailures: 2,
+		Cooldown:    time.Minute,
+		Relays: []RelayConfig{
+			{Name: "backup", URL: "smtp://backup", Priority: 2},
+			{Name: "primary-a", URL: "smtp://a", Priority: 1, Weight: 3},
+			{Name: "primary-b", URL: "smtp://b", Priority: 1, Weight: 1},
+		},
+	})
+
+	// Inherited TLS configuration
+	if p.relays[0].config.TLS != tls {
+		t.Errorf("Relay should inherit TLS config")
+	}
+
+	// Priority groups are in order, and weighted within
+	counts := map[string]int{}
+	for range 1000 {
+		c := names(p.candidates(time.Now()))
+		if len(c) != 3 || c[2] != "backup" {
+			t.Fatalf("Unexpected candidates: %v", c)
+		}
+		counts[c[0]]++
+	}
+	if a := counts["primary-a"]; a < 650 || a > 850 {
+		t.Errorf("Expected ~750 of 1000 picks of weighted relay, got %d", a)
+	}
+
+	// Failover after consecutive failures
+	primaryA := p.relays[0]
+	if p.failed(primaryA) {
+		t.Error("Relay should not fail over on first failure")
+	}
+	p.succeeded(primaryA)
+	if p.failed(primaryA) {
+		t.Error("Success should reset failures")
+	}
+	if !p.failed(primaryA) {
+		t.Error("Relay should fail over on second failure")
+	}
+
+	c := names(p.candidates(time.Now()))
+	if c[0] != "primary-b" || c[1] != "backup" || c[2] != "primary-a" {
+		t.Errorf("Failed relay should be last: %v", c)
+	}
+
+	// Back in rotation after cooldown
+	c = names(p.candidates(time.Now().Add(2 * time.Minute)))
+	if c[2] != "backup" {
+		t.Errorf("Relay should recover after cooldown: %v", c)
+	}
+}
+
+func TestNewConnFailover(t *testing.T) {
+	s := NewSMTPSender(t.Context(), &SMTPConfig{
+		Relays: []RelayConfig{
+			{Name: "broken", URL: "https://invalid"},
+			{Name: "also-broken", URL: "only.host", Priority: 1},
+		},
+	})
+
+	_, err := s.NewConn()
+	if err == nil || !strings.Contains(err.Error(), "only.host") {
+		t.Fatalf("NewConn() should fail with last relay's error: %v", err)
+	}
+	for _, r := range s.relays.relays {
+		if r.downUntil.IsZero() {
+			t.Errorf("Relay %s should be down", r.name)
+		}
+	}
+}
+
+func names(relays []*relay) []string {
+	out := make([]string, len(relays))
+	for i, r := range relays {
+		out[i] = r.name
+	}
+	return out
+}
+
+func TestMemoryQueue_RelaysReconnect(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+
+	// Deliveries are handed over before the server replies
+	delivered := make(chan string)
+	startTestSMTP(t, l, delivered)
+
+	// Primary relay is briefly unreachable after its first session,
+	// while backup is broken, so all relays are down for a moment
+	s := NewSMTPSender(t.Context(), &SMTPConfig{
+		Relays: []RelayConfig{
+			{Name: "primary", URL: "smtp://" + addr},
+			{Name: "backup", URL: "https://invalid", Priority: 1},
+		},
+	})
+
+	cfg := &Config{
+		Workers:      1,
+		BatchSize:    1,
+		SessionLimit: 1,
+		Retry:        RetryConfig{InitialInterval: 10 * time.Millisecond},
+	}
+	queue := newTestQueue(t, cfg, s)
+	enqueueTo(t, queue, testRecipients(3)...)
+	queue.Close()
+
+	<-delivered
+	l.Close()
+	time.Sleep(2500 * time.Millisecond) // Longer than dial retries
+	if l, err = net.Listen("tcp", addr); err != nil {
+		t.Fatal(err)
+	}
+	startTestSMTP(t, l, delivered)
+
+	for range 2 {
+		<-delivered
+	}
+	if err := queue.Wait(); err != nil {
+		t.Fatalf("Wait() failed: %v", err)
+	}
+}
+
+// Minimal SMTP server, which reports the recipient of each delivered message
+func startTestSMTP(t *testing.T, l net.Listener, out chan<- string) {
+	t.Helper()
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			nc, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go serveTestSMTP(textproto.NewConn(nc), out)
+		}
+	}()
+}
+
+func serveTestSMTP(c *textproto.Conn, out chan<- string) {
+	defer c.Close()
+
+	var rcpt string
+	c.PrintfLine("220 localhost ESMTP ready")
+	for {
+		line, err := c.ReadLine()
+		if err != nil {
+			return
+		}
+
+		cmd, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(cmd) {
+		case "EHLO", "HELO":
+			c.PrintfLine("250 localhost")
+		case "MAIL", "RSET", "NOOP":
+			c.PrintfLine("250 2.0.0 Ok")
+		case "RCPT":
+			rcpt = arg
+			c.PrintfLine("250 2.1.5 Ok")
+		case "DATA":
+			c.PrintfLine("354 Go ahead")
+			c.ReadDotBytes()
+			out <- rcpt
+			c.PrintfLine("250 2.0.0 Delivered")
+		case "QUIT":
+			c.PrintfLine("221 Bye")
+			return
+		default:
+			c.PrintfLine("500 Unknown command")
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package send
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/wneessen/go-mail"
+)
+
+// Delivery states of a message
+const (
+	StateQueued   = "queued"
+	StateSent     = "sent"
+	StateDeferred = "deferred" // Temporary failure, will retry
+	StateFailed   = "failed"   // Temporary failure, out of retries
+	StateBounced  = "bounced"  // Permanent failure
+)
+
+// Result is the outcome of a single delivery attempt
+type Result struct {
+	Time      time.Time `json:"time"`
+	Campaign  string    `json:"campaign"`
+	Index     int       `json:"index"`
+	Email     string    `json:"email"`
+	MessageID string    `json:"messageId"`
+	State     string    `json:"state"`
+
+	// SMTP reply code, enhanced status and text
+	Code     int    `json:"code,omitempty"`
+	Status   string `json:"status,omitempty"`
+	Response string `json:"response,omitempty"`
+
+	Worker   int    `json:"worker"`
+	Attempts int    `json:"attempts"`
+	Relay    string `json:"relay,omitempty"`
+}
+
+// Failed is true for messages that will not be delivered
+func (r *Result) Failed() bool {
+	return r.State == StateFailed || r.State == StateBounced
+}
+
+// Reporter receives the outcome of every delivery attempt
+type Reporter interface {
+	Report(r *Result)
+}
+
+// ReporterFunc allows the use of ordinary functions as a Reporter
+type ReporterFunc func(r *Result)
+
+func (f ReporterFunc) Report(r *Result) {
+	f(r)
+}
+
+func newResult(queueID string, worker int, t *task, err error) *Result {
+	r := &Result{
+		Time:      time.Now(),
+		Campaign:  queueID,
+		Index:     t.index,
+		Email:     t.recipient(),
+		MessageID: t.msg.GetMessageID(),
+		State:     StateSent,
+		Worker:    worker,
+		Attempts:  t.attempts,
+	}
+
+	// Successful SMTP DATA is always acknowledged with 250,
+	// even if the connection failed after message was accepted
+	if err == nil || t.msg.IsDelivered() {
+		if r.Response = t.msg.ServerResponse(); r.Response != "" {
+			r.Code = 250
+		}
+		return r
+	}
+
+	r.State = StateBounced
+	r.Response = err.Error()
+	var sendErr *mail.SendError
+	var transportErr *TransportError
+	if errors.As(err, &sendErr) {
+		r.Code = sendErr.ErrorCode()
+		r.Status = sendErr.EnhancedStatusCode()
+	} else if errors.As(err, &transportErr) {
+		r.Code = transportErr.Code
+		r.Status = transportErr.Status
+	}
+
+	if isTemporary(err) {
+		r.State = StateFailed
+	}
+
+	return r
+}
+
+// Maximum number of failures listed in DeliveryError message
+const maxListedFailures = 5
+
+// DeliveryError summarizes failed deliveries of a queue
+type DeliveryError struct {
+	Total  int
+	Failed []*Result
+}
+
+func (e *DeliveryError) Error() string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "failed to deliver %d of %d emails", len(e.Failed), e.Total)
+	for i, r := range e.Failed {
+		if i == maxListedFailures {
+			fmt.Fprintf(&out, "; and %d more", len(e.Failed)-i)
+			break
+		}
+		fmt.Fprintf(&out, "; %s: %s", r.Email, r.Response)
+	}
+	return out.String()
+}
+
+// deliveryStats aggregates results for Manager.Wait
+type deliveryStats struct {
+	total  int
+	failed []*Result
+}
+
+func (s *deliveryStats) add(r *Result) {
+	if r.State == StateDeferred {
+		return // Not final
+	}
+	s.total++
+	if r.Failed() {
+		s.failed = append(s.failed, r)
+	}
+}
+
+func (s *deliveryStats) err() error {
+	if len(s.failed) == 0 {
+		return nil
+	}
+	return &DeliveryError{Total: s.total, Failed: s.failed}
+}
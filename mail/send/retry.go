@@ -0,0 +1,71 @@
+package send
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cenkalti/backoff/v5"
+	"github.com/wneessen/go-mail"
+)
+
+// Retry policy for temporary delivery failures (4xx, dropped connections)
+type RetryConfig struct {
+	MaxAttempts     int // Including the first one
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	Multiplier      float64
+}
+
+// next returns a delay before the task's next attempt, or 0 if out of retries
+func (c RetryConfig) next(t *task) time.Duration {
+	if t.attempts >= c.MaxAttempts {
+		return 0
+	}
+
+	if t.backoff == nil {
+		t.backoff = c.newBackOff()
+	}
+
+	return max(t.backoff.NextBackOff(), time.Millisecond)
+}
+
+// newBackOff of retry intervals, which is also used for reconnecting
+func (c RetryConfig) newBackOff() *backoff.ExponentialBackOff {
+	b := backoff.NewExponentialBackOff()
+	if c.InitialInterval > 0 {
+		b.InitialInterval = c.InitialInterval
+	}
+	if c.MaxInterval > 0 {
+		b.MaxInterval = c.MaxInterval
+	}
+	if c.Multiplier > 0 {
+		b.Multiplier = c.Multiplier
+	}
+	return b
+}
+
+// isTemporary classifies delivery errors that are worth retrying:
+// SMTP 4xx replies, and failures without a reply (e.g. broken connection)
+func isTemporary(err error) bool {
+	var transportErr *TransportError
+	if errors.As(err, &transportErr) {
+		return transportErr.Temporary()
+	}
+
+	var sendErr *mail.SendError
+	if !errors.As(err, &sendErr) {
+		return true // Dial, network, etc
+	} else if sendErr.IsTemp() {
+		return true
+	} else if sendErr.ErrorCode() >= 500 {
+		return false
+	}
+
+	// No SMTP reply, so it depends on the step that failed
+	switch sendErr.Reason {
+	case mail.ErrGetSender, mail.ErrGetRcpts, mail.ErrNoUnencoded:
+		return false // Broken message
+	default:
+		return true
+	}
+}
@@ -0,0 +1,164 @@
+package send
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/wneessen/go-mail"
+)
+
+func TestIsTemporary(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		temp bool
+	}{
+		{"Network error", errors.New("connection reset"), true},
+		{"Wrapped network error", fmt.Errorf("dial: %w", errors.New("timeout")), true},
+		{"Connection check", &mail.SendError{Reason: mail.ErrConnCheck}, true},
+		{"Broken DATA", &mail.SendError{Reason: mail.ErrWriteContent}, true},
+		{"Invalid sender", &mail.SendError{Reason: mail.ErrGetSender}, false},
+		{"Invalid recipients", &mail.SendError{Reason: mail.ErrGetRcpts}, false},
+		{"Joined permanent", errors.Join(&mail.SendError{Reason: mail.ErrNoUnencoded}), false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if temp := isTemporary(c.err); temp != c.temp {
+				t.Errorf("isTemporary(%v) = %t, expected %t", c.err, temp, c.temp)
+			}
+		})
+	}
+}
+
+func TestRetryConfigNext(t *testing.T) {
+	c := RetryConfig{MaxAttempts: 3, InitialInterval: time.Second, Multiplier: 2}
+	tk := &task{attempts: 1}
+
+	if d := c.next(tk); d < 500*time.Millisecond || d > 1500*time.Millisecond {
+		t.Errorf("Unexpected first delay: %s", d)
+	}
+
+	tk.attempts = 2
+	if d := c.next(tk); d < time.Second || d > 3*time.Second {
+		t.Errorf("Unexpected second delay: %s", d)
+	}
+
+	tk.attempts = 3
+	if d := c.next(tk); d != 0 {
+		t.Errorf("Should not retry after max attempts: %s", d)
+	}
+
+	if d := (RetryConfig{}).next(&task{attempts: 1}); d != 0 {
+		t.Errorf("Should not retry by default: %s", d)
+	}
+}
+
+func TestMemoryQueue_RetryTemporaryFailure(t *testing.T) {
+	results := retryResults(t, func(attempt int32) error {
+		if attempt < 3 {
+			return errors.New("421 try again later")
+		}
+		return nil
+	})
+
+	states := []string{}
+	for _, r := range results {
+		states = append(states, r.State)
+	}
+	expected := []string{StateDeferred, StateDeferred, StateSent}
+	if fmt.Sprint(states) != fmt.Sprint(expected) {
+		t.Errorf("Unexpected states %v, expected %v", states, expected)
+	}
+	if a := results[2].Attempts; a != 3 {
+		t.Errorf("Expected 3 attempts, got %d", a)
+	}
+}
+
+func TestMemoryQueue_RetryExhausted(t *testing.T) {
+	results := retryResults(t, func(attempt int32) error {
+		return errors.New("421 try again later")
+	})
+
+	if l := len(results); l != 3 {
+		t.Fatalf("Expected 3 attempts, got %d", l)
+	}
+	if s := results[2].State; s != StateFailed {
+		t.Errorf("Expected final state %s, got %s", StateFailed, s)
+	}
+}
+
+func TestMemoryQueue_NoRetryPermanentFailure(t *testing.T) {
+	results := retryResults(t, func(attempt int32) error {
+		return &mail.SendError{Reason: mail.ErrGetRcpts}
+	})
+
+	if l := len(results); l != 1 {
+		t.Fatalf("Expected 1 attempt, got %d", l)
+	}
+	if s := results[0].State; s != StateBounced {
+		t.Errorf("Expected state %s, got %s", StateBounced, s)
+	}
+}
+
+// Delivers a single message through a retrying queue,
+// where send fails with fn, and returns all results
+func retryResults(t *testing.T, fn func(attempt int32) error) []*Result {
+	t.Helper()
+	cfg := &Config{
+		QueueID: "test-campaign",
+		Workers: 1,
+		Retry:   RetryConfig{MaxAttempts: 3, InitialInterval: 10 * time.Millisecond},
+	}
+	results := recordResults(cfg)
+
+	var attempts atomic.Int32
+	runQueue(t, cfg, sendFunc(func(...*mail.Msg) error {
+		return fn(attempts.Add(1))
+	}), []string{"test@example.com"})
+	return results()
+}
+
+func TestMemoryQueue_Reconnect(t *testing.T) {
+	// Second session fails to connect once
+	var mu sync.Mutex
+	var sent []string
+	var dials atomic.Int32
+	sender := &mockSender{connFunc: func() (Conn, error) {
+		if dials.Add(1) == 2 {
+			return nil, errors.New("connection refused")
+		}
+		return &mockConn{sendFunc: func(msgs ...*mail.Msg) error {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, m := range msgs {
+				sent = append(sent, m.GetTo()[0].Address)
+			}
+			return nil
+		}}, nil
+	}}
+
+	cfg := &Config{
+		Workers:      1,
+		BatchSize:    1,
+		SessionLimit: 2,
+		Retry:        RetryConfig{InitialInterval: 10 * time.Millisecond},
+	}
+	to := testRecipients(6)
+	if err := runQueue(t, cfg, sender, to); err != nil {
+		t.Fatalf("Wait() failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	slices.Sort(sent)
+	slices.Sort(to)
+	if !slices.Equal(sent, to) {
+		t.Errorf("Expected all recipients delivered, got %v", sent)
+	}
+}
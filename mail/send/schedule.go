@@ -0,0 +1,157 @@
+package send
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Accepted formats of scheduled time, besides RFC 3339
+var scheduleLayouts = []string{
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// Schedule holds messages until they are eligible for delivery
+type Schedule struct {
+	At       time.Time      // Not before (zero for immediately)
+	Window   Window         // Daily delivery window (zero for any time)
+	Location *time.Location // Window timezone, unless recipient has one
+}
+
+// ParseSchedule parses time (RFC 3339 or "YYYY-MM-DD HH:MM"), and a
+// daily window ("HH:MM-HH:MM") in timezone (IANA name, or local if blank)
+func ParseSchedule(at, window, timezone string) (Schedule, error) {
+	var s Schedule
+	var err error
+
+	if s.Location, err = time.LoadLocation(timezone); err != nil {
+		return s, fmt.Errorf("invalid timezone %q: %w", timezone, err)
+	}
+
+	if at != "" {
+		if s.At, err = parseScheduleTime(at, s.Location); err != nil {
+			return s, err
+		}
+	}
+
+	if window != "" {
+		if s.Window, err = ParseWindow(window); err != nil {
+			return s, err
+		}
+	}
+
+	return s, nil
+}
+
+func parseScheduleTime(at string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, at); err == nil {
+		return t, nil
+	}
+	for _, layout := range scheduleLayouts {
+		if t, err := time.ParseInLocation(layout, at, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (expected RFC 3339 or YYYY-MM-DD HH:MM)", at)
+}
+
+// IsZero is true for schedule without any restrictions
+func (s Schedule) IsZero() bool {
+	return s.At.IsZero() && s.Window.IsZero()
+}
+
+// next returns the earliest eligible time at or after t,
+// where window is in loc (or schedule's location if nil)
+func (s Schedule) next(t time.Time, loc *time.Location) time.Time {
+	if t.Before(s.At) {
+		t = s.At
+	}
+
+	if s.Window.IsZero() {
+		return t
+	} else if loc == nil {
+		loc = s.Location
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+
+	lt := t.In(loc)
+	if s.Window.contains(lt) {
+		return t
+	}
+
+	// Next opening, today or tomorrow
+	start := s.Window.on(lt)
+	if !start.After(lt) {
+		start = s.Window.on(lt.AddDate(0, 0, 1))
+	}
+	return start
+}
+
+// Window is a daily delivery window, where End before
+// Start wraps around midnight (e.g. "22:00-06:00")
+type Window struct {
+	Start, End time.Duration // Since midnight
+}
+
+// ParseWindow parses "HH:MM-HH:MM"
+func ParseWindow(s string) (Window, error) {
+	from, to, ok := strings.Cut(s, "-")
+	start, err1 := parseClock(from)
+	end, err2 := parseClock(to)
+	if !ok || err1 != nil || err2 != nil || start == end {
+		return Window{}, fmt.Errorf("invalid window %q (expected HH:MM-HH:MM)", s)
+	}
+	return Window{Start: start, End: end}, nil
+}
+
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func (w Window) IsZero() bool {
+	return w.Start == 0 && w.End == 0
+}
+
+func (w Window) String() string {
+	clock := func(d time.Duration) string {
+		return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return clock(w.Start) + "-" + clock(w.End)
+}
+
+// contains checks if local time of t is within the window
+func (w Window) contains(t time.Time) bool {
+	h, m, s := t.Clock()
+	clock := time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(s)*time.Second
+	if w.Start < w.End {
+		return clock >= w.Start && clock < w.End
+	}
+	return clock >= w.Start || clock < w.End
+}
+
+// on returns the window's opening on day of t
+func (w Window) on(t time.Time) time.Time {
+	y, mo, d := t.Date()
+	h, m := int(w.Start.Hours()), int(w.Start.Minutes())%60
+	return time.Date(y, mo, d, h, m, 0, 0, t.Location())
+}
+
+// WithTimezone attaches the recipient's timezone to an Enqueue context
+func WithTimezone(ctx context.Context, loc *time.Location) context.Context {
+	return context.WithValue(ctx, ctxTimezoneKey, loc)
+}
+
+// Accessor for recipient's timezone from context
+func Timezone(ctx context.Context) (*time.Location, bool) {
+	loc, ok := ctx.Value(ctxTimezoneKey).(*time.Location)
+	return loc, ok && loc != nil
+}
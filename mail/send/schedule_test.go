@@ -0,0 +1,140 @@
+package send
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wneessen/go-mail"
+)
+
+func TestParseSchedule(t *testing.T) {
+	ny, _ := time.LoadLocation("America/New_York")
+
+	s, err := ParseSchedule("2025-03-01 09:30", "09:00-17:00", "America/New_York")
+	if err != nil {
+		t.Fatalf("ParseSchedule() failed: %v", err)
+	}
+	if expected := time.Date(2025, 3, 1, 9, 30, 0, 0, ny); !s.At.Equal(expected) {
+		t.Errorf("Unexpected time %s, expected %s", s.At, expected)
+	}
+	if w := s.Window.String(); w != "09:00-17:00" {
+		t.Errorf("Unexpected window %s", w)
+	}
+
+	s, err = ParseSchedule("2025-03-01T09:30:00Z", "", "")
+	if err != nil || !s.At.Equal(time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected RFC 3339 time %s: %v", s.At, err)
+	}
+	if !s.Window.IsZero() || s.IsZero() {
+		t.Errorf("Unexpected empty window %v", s.Window)
+	}
+
+	failures := [][3]string{
+		{"tomorrow", "", ""},
+		{"", "9-5", ""},
+		{"", "09:00-09:00", ""},
+		{"", "", "Mars/Olympus"},
+	}
+	for _, f := range failures {
+		if _, err := ParseSchedule(f[0], f[1], f[2]); err == nil {
+			t.Errorf("ParseSchedule(%q) should fail", f)
+		}
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	utc := time.UTC
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	day := func(h, m int) time.Time { return time.Date(2025, 3, 1, h, m, 0, 0, utc) }
+
+	business := Window{Start: 9 * time.Hour, End: 17 * time.Hour}
+	night := Window{Start: 22 * time.Hour, End: 6 * time.Hour}
+
+	cases := []struct {
+		name     string
+		schedule Schedule
+		now      time.Time
+		loc      *time.Location
+		expected time.Time
+	}{
+		{"Unrestricted", Schedule{}, day(3, 0), nil, day(3, 0)},
+		{"Before time", Schedule{At: day(12, 0)}, day(3, 0), nil, day(12, 0)},
+		{"After time", Schedule{At: day(12, 0)}, day(13, 0), nil, day(13, 0)},
+		{"In window", Schedule{Window: business, Location: utc}, day(10, 0), nil, day(10, 0)},
+		{"Before window", Schedule{Window: business, Location: utc}, day(7, 0), nil, day(9, 0)},
+		{"After window", Schedule{Window: business, Location: utc}, day(17, 0), nil, day(9, 0).AddDate(0, 0, 1)},
+		{"Time before window", Schedule{At: day(5, 0), Window: business, Location: utc}, day(3, 0), nil, day(9, 0)},
+		{"Overnight window", Schedule{Window: night, Location: utc}, day(23, 0), nil, day(23, 0)},
+		{"Overnight early", Schedule{Window: night, Location: utc}, day(5, 0), nil, day(5, 0)},
+		{"Overnight closed", Schedule{Window: night, Location: utc}, day(12, 0), nil, day(22, 0)},
+		{"Recipient timezone", Schedule{Window: business, Location: utc}, day(3, 0), tokyo, day(3, 0)},
+		{"Recipient timezone closed", Schedule{Window: business, Location: utc}, day(10, 0), tokyo, day(0, 0).AddDate(0, 0, 1)},
+	}
+
+	for _, c := range cases {
+		if next := c.schedule.next(c.now, c.loc); !next.Equal(c.expected) {
+			t.Errorf("%s: next is %s, expected %s", c.name, next.UTC(), c.expected)
+		}
+	}
+}
+
+func TestMemoryQueue_Schedule(t *testing.T) {
+	var mu sync.Mutex
+	var sentAt []time.Time
+	sender := &mockSender{
+		connFunc: func() (Conn, error) {
+			return &mockConn{sendFunc: func(msgs ...*mail.Msg) error {
+				mu.Lock()
+				for range msgs {
+					sentAt = append(sentAt, time.Now())
+				}
+				mu.Unlock()
+				return nil
+			}}, nil
+		},
+	}
+
+	ctx := context.Background()
+	at := time.Now().Add(100 * time.Millisecond)
+	queue, err := NewInMemory(ctx, &Config{
+		Workers:  2,
+		SendRate: 5, // Not applied to Enqueue of held emails
+		Schedule: Schedule{At: at},
+	}, sender)
+	if err != nil {
+		t.Fatalf("NewInMemory() failed: %v", err)
+	}
+
+	start := time.Now()
+	for _, to := range []string{"a@example.com", "b@example.com"} {
+		msg := mail.NewMsg()
+		msg.To(to)
+		if err := queue.Enqueue(WithTimezone(ctx, time.UTC), msg); err != nil {
+			t.Fatalf("Enqueue() failed: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("Enqueue of held emails should not be throttled: %s", elapsed)
+	}
+
+	queue.Close()
+	if err := queue.Wait(); err != nil {
+		t.Fatalf("Wait() failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(sentAt) != 2 {
+		t.Fatalf("Expected 2 sent emails, got %d", len(sentAt))
+	}
+	for _, s := range sentAt {
+		if s.Before(at) {
+			t.Errorf("Email sent at %s, before schedule %s", s, at)
+		}
+	}
+	if gap := sentAt[1].Sub(sentAt[0]); gap < 150*time.Millisecond {
+		t.Errorf("Held emails should be sent at global rate, got %s apart", gap)
+	}
+}
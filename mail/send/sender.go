@@ -5,6 +5,7 @@ import (
 	"errors"
 
 	"github.com/cenkalti/backoff/v5"
+	"github.com/spf13/afero"
 	"github.com/wneessen/go-mail"
 
 	"bytes"
@@ -17,7 +18,7 @@ import (
 )
 
 func NewSMTPSender(ctx context.Context, cfg *SMTPConfig) *smtpSender {
-	return &smtpSender{ctx, cfg}
+	return &smtpSender{ctx, newRelayPool(cfg)}
 }
 
 func NewTestSender() *testSender {
@@ -29,6 +30,18 @@ type SMTPConfig struct {
 	User string
 	Pass string
 	TLS  *TLSConfig
+
+	// Request headers for HTTP transports (e.g. Authorization)
+	Headers map[string]string
+
+	// Multiple relays with failover (overrides URL)
+	Relays      []RelayConfig
+	MaxFailures int           // Consecutive failures before failover
+	Cooldown    time.Duration // Before failed relay is retried
+
+	// Filesystem of relative file, maildir and mbox
+	// destinations (e.g. project, instead of working directory)
+	Fs afero.Fs `mapstructure:"-"`
 }
 
 type TLSConfig struct {
@@ -56,13 +69,26 @@ func (t TLSConfig) GetMinVersion() (uint16, error) {
 
 type smtpSender struct {
 	context context.Context
-	config  *SMTPConfig
+	relays  *relayPool
 }
 
+// NewConn dials the preferred relay, failing over to others
 func (d smtpSender) NewConn() (conn Conn, err error) {
-	cfg := d.config
-	ctx := d.context
+	for _, r := range d.relays.candidates(time.Now()) {
+		var client *mail.Client
+		if client, err = smtpDial(d.context, &r.config); err == nil {
+			return &relayConn{Client: client, relay: r, pool: d.relays}, nil
+		} else if d.context.Err() != nil {
+			break
+		}
+
+		d.relays.down(r)
+		logRelayDown(r, err)
+	}
+	return nil, err
+}
 
+func smtpDial(ctx context.Context, cfg *SMTPConfig) (*mail.Client, error) {
 	// Initialize dialer from configuration
 	dialer, err := smtpDialer(cfg)
 	if err != nil {
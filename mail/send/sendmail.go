@@ -0,0 +1,101 @@
+package send
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/url"
+	"os/exec"
+	"strings"
+
+	"github.com/wneessen/go-mail"
+)
+
+// Exit codes of sendmail (see sysexits.h)
+const (
+	exNoUser   = 67
+	exNoHost   = 68
+	exTempFail = 75
+)
+
+// sendmailSender pipes each message to a local "sendmail" with its recipients
+type sendmailSender struct {
+	context context.Context
+	path    string
+}
+
+func newSendmailSender(ctx context.Context, _ *SMTPConfig, u *url.URL) (Sender, error) {
+	path := localPath(u)
+	if path == "" {
+		path = mail.SendmailPath
+	}
+
+	path, err := exec.LookPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sendmailSender{context: ctx, path: path}, nil
+}
+
+// Every message starts its own process, so workers share the sender
+func (s *sendmailSender) NewConn() (Conn, error) {
+	return s, nil
+}
+
+func (s *sendmailSender) Send(msgs ...*mail.Msg) error {
+	return sendEach(msgs, s.send)
+}
+
+func (s *sendmailSender) send(msg *mail.Msg) error {
+	var in, stderr bytes.Buffer
+	if _, err := msg.WriteTo(&in); err != nil {
+		return &TransportError{Code: 554, Status: "5.6.0", Response: err.Error()}
+	}
+
+	// Envelope recipients, including Bcc, which is not in the headers
+	rcpts, err := msg.GetRecipients()
+	if err != nil {
+		return &TransportError{Code: 554, Status: "5.1.3", Response: err.Error()}
+	}
+
+	// Envelope sender, only if set apart from "From"
+	args := []string{"-oi"}
+	if ef := msg.GetAddrHeader(mail.HeaderEnvelopeFrom); len(ef) > 0 {
+		args = append(args, "-f", ef[0].Address)
+	}
+	args = append(args, "--")
+	for _, r := range rcpts {
+		args = append(args, strings.Trim(r, "<>"))
+	}
+
+	cmd := exec.CommandContext(s.context, s.path, args...)
+	cmd.Stdin, cmd.Stderr = &in, &stderr
+
+	var exitErr *exec.ExitError
+	if err := cmd.Run(); !errors.As(err, &exitErr) {
+		return err // Success, or could not run
+	}
+
+	e := &TransportError{Response: strings.TrimSpace(stderr.String())}
+	if e.Response == "" {
+		e.Response = exitErr.Error()
+	}
+
+	switch exitErr.ExitCode() {
+	case exTempFail:
+		e.Code, e.Status = 451, "4.3.0"
+	case exNoUser:
+		e.Code, e.Status = 550, "5.1.1"
+	case exNoHost:
+		e.Code, e.Status = 550, "5.1.2"
+	default:
+		e.Code, e.Status = 554, "5.3.0"
+	}
+
+	return e
+}
+
+func (s *sendmailSender) Close() error {
+	return nil
+}
@@ -0,0 +1,93 @@
+package send
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSendmailSender(t *testing.T) {
+	dir := t.TempDir()
+	script := writeScript(t, dir, "sendmail", `echo "$@" > "$(dirname "$0")/args"; cat > "$(dirname "$0")/out.eml"`)
+	s := newTestTransport(t, "sendmail://"+script)
+
+	// Bcc is only in the arguments
+	msg := newTestMsg(t)
+	if err := msg.Bcc("Carol <carol@example.com>"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Send(msg); err != nil {
+		t.Fatalf("Send() failed: %v", err)
+	}
+
+	args, _ := os.ReadFile(filepath.Join(dir, "args"))
+	if a := strings.TrimSpace(string(args)); a != "-oi -- bob@example.com carol@example.com" {
+		t.Errorf("Unexpected sendmail arguments: %s", a)
+	}
+	if raw, _ := os.ReadFile(filepath.Join(dir, "out.eml")); !strings.Contains(string(raw), "Subject: Hello") {
+		t.Errorf("Unexpected sendmail input: %s", raw)
+	}
+}
+
+func TestSendmailSender_EnvelopeFrom(t *testing.T) {
+	dir := t.TempDir()
+	script := writeScript(t, dir, "sendmail", `echo "$@" > "$(dirname "$0")/args"; cat > /dev/null`)
+	s := newTestTransport(t, "sendmail://"+script)
+
+	msg := newTestMsg(t)
+	if err := msg.EnvelopeFrom("bounces+jane=example.com@example.org"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Send(msg); err != nil {
+		t.Fatalf("Send() failed: %v", err)
+	}
+
+	args, _ := os.ReadFile(filepath.Join(dir, "args"))
+	if a := strings.TrimSpace(string(args)); a != "-oi -f bounces+jane=example.com@example.org -- bob@example.com" {
+		t.Errorf("Unexpected sendmail arguments: %s", a)
+	}
+}
+
+func TestSendmailSender_ExitCodes(t *testing.T) {
+	cases := []struct {
+		exit int
+		code int
+		temp bool
+	}{
+		{75, 451, true},
+		{67, 550, false},
+		{1, 554, false},
+	}
+
+	for _, c := range cases {
+		dir := t.TempDir()
+		script := writeScript(t, dir, "sendmail", "cat > /dev/null; echo oops >&2; exit "+strconv.Itoa(c.exit))
+		err := newTestTransport(t, "sendmail://"+script).Send(newTestMsg(t))
+
+		var te *TransportError
+		if !errors.As(err, &te) || te.Code != c.code || te.Response != "oops" {
+			t.Errorf("Exit %d: unexpected error %v", c.exit, err)
+		} else if isTemporary(err) != c.temp {
+			t.Errorf("Exit %d: isTemporary should be %t", c.exit, c.temp)
+		}
+	}
+}
+
+func TestSendmailSender_Missing(t *testing.T) {
+	_, err := NewSender(t.Context(), &SMTPConfig{URL: "sendmail:///does/not/exist"})
+	if err == nil {
+		t.Error("NewSender() should fail for missing binary")
+	}
+}
+
+func writeScript(t *testing.T, dir, name, body string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
@@ -0,0 +1,102 @@
+package send
+
+import (
+	"container/heap"
+	"context"
+	"time"
+
+	"github.com/cenkalti/backoff/v5"
+	"github.com/wneessen/go-mail"
+)
+
+type contextKey int
+
+const (
+	ctxRecipientIndexKey contextKey = iota
+	ctxTimezoneKey
+	ctxJobKey
+)
+
+// task is a single message travelling through the queue
+type task struct {
+	msg *mail.Msg
+
+	// Recipient index in the campaign's list (-1 if unknown)
+	index int
+
+	// Delivery attempts so far
+	attempts int
+	backoff  *backoff.ExponentialBackOff
+
+	// Delayed until this time
+	readyAt time.Time
+
+	// Recipient domain limits, and whether a slot is reserved
+	limit    *domainLimit
+	reserved bool
+
+	// Recipient timezone for delivery window, and
+	// whether task was held for its schedule
+	loc       *time.Location
+	scheduled bool
+}
+
+func newTask(ctx context.Context, msg *mail.Msg) *task {
+	t := &task{msg: msg, index: -1}
+	if i, ok := RecipientIndex(ctx); ok {
+		t.index = i
+	}
+	if loc, ok := Timezone(ctx); ok {
+		t.loc = loc
+	}
+	return t
+}
+
+// Primary recipient address, used for logging and state
+func (t *task) recipient() string {
+	if to := t.msg.GetTo(); len(to) > 0 {
+		return to[0].Address
+	}
+	return ""
+}
+
+// WithRecipientIndex attaches the recipient's list index to an Enqueue context
+func WithRecipientIndex(ctx context.Context, i int) context.Context {
+	return context.WithValue(ctx, ctxRecipientIndexKey, i)
+}
+
+// Accessor for recipient's list index from context
+func RecipientIndex(ctx context.Context) (int, bool) {
+	i, ok := ctx.Value(ctxRecipientIndexKey).(int)
+	return i, ok
+}
+
+// taskHeap keeps delayed tasks ordered by their readyAt time
+type taskHeap []*task
+
+func (h taskHeap) Len() int           { return len(h) }
+func (h taskHeap) Less(i, j int) bool { return h[i].readyAt.Before(h[j].readyAt) }
+func (h taskHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *taskHeap) Push(x any)        { *h = append(*h, x.(*task)) }
+func (h *taskHeap) Pop() any {
+	old, n := *h, len(*h)
+	t := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return t
+}
+
+func (h *taskHeap) push(t *task) {
+	heap.Push(h, t)
+}
+
+// popReady removes the earliest task if it is ready, otherwise
+// returns the wait until it is (or zero for an empty heap)
+func (h *taskHeap) popReady(now time.Time) (*task, time.Duration) {
+	if h.Len() == 0 {
+		return nil, 0
+	} else if wait := (*h)[0].readyAt.Sub(now); wait > 0 {
+		return nil, wait
+	}
+	return heap.Pop(h).(*task), 0
+}
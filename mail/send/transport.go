@@ -0,0 +1,89 @@
+package send
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+)
+
+// TransportFunc creates a Sender for a delivery URL
+type TransportFunc func(ctx context.Context, cfg *SMTPConfig, u *url.URL) (Sender, error)
+
+// Transports by URL scheme
+var transports = map[string]TransportFunc{}
+
+// RegisterTransport makes a delivery transport available for URL scheme
+func RegisterTransport(scheme string, fn TransportFunc) {
+	transports[scheme] = fn
+}
+
+func init() {
+	smtp := func(ctx context.Context, cfg *SMTPConfig, _ *url.URL) (Sender, error) {
+		return NewSMTPSender(ctx, cfg), nil
+	}
+	RegisterTransport("", smtp)
+	RegisterTransport("smtp", smtp)
+	RegisterTransport("smtps", smtp)
+
+	RegisterTransport("http", newHTTPSender)
+	RegisterTransport("https", newHTTPSender)
+	RegisterTransport("http+json", newHTTPSender)
+	RegisterTransport("https+json", newHTTPSender)
+
+	RegisterTransport("sendmail", newSendmailSender)
+	RegisterTransport("lmtp", newLMTPSender)
+
+	RegisterTransport("file", newFileSender)
+	RegisterTransport("maildir", newFileSender)
+	RegisterTransport("mbox", newFileSender)
+}
+
+// NewSender creates a Sender for transport in delivery URL's scheme
+func NewSender(ctx context.Context, cfg *SMTPConfig) (Sender, error) {
+	if len(cfg.Relays) > 0 {
+		return NewSMTPSender(ctx, cfg), nil
+	}
+
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	fn, ok := transports[u.Scheme]
+	if !ok {
+		return nil, fmt.Errorf("unknown delivery transport %q (supported: %v)", u.Scheme, transportSchemes())
+	}
+
+	return fn(ctx, cfg, u)
+}
+
+func transportSchemes() (out []string) {
+	for s := range transports {
+		if s != "" {
+			out = append(out, s)
+		}
+	}
+	sort.Strings(out)
+	return
+}
+
+// TransportError is a delivery failure of a non-SMTP transport,
+// translated to its equivalent SMTP reply for results and retries
+type TransportError struct {
+	Code     int    // SMTP reply code
+	Status   string // Enhanced status code
+	Response string
+}
+
+func (e *TransportError) Error() string {
+	if e.Status == "" {
+		return fmt.Sprintf("%d %s", e.Code, e.Response)
+	}
+	return fmt.Sprintf("%d %s %s", e.Code, e.Status, e.Response)
+}
+
+// Temporary is true for 4xx replies
+func (e *TransportError) Temporary() bool {
+	return e.Code < 500
+}
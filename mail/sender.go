@@ -3,10 +3,15 @@ package mail
 import (
 	"github.com/rykov/paperboy/config"
 	"github.com/rykov/paperboy/mail/send"
+	"github.com/spf13/afero"
+	"github.com/spf13/cast"
 	"github.com/wneessen/go-mail"
 
 	"errors"
 	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
 )
 
 func LoadAndSendCampaign(cfg *config.AConfig, tmplFile, recipientFile string) error {
@@ -19,17 +24,60 @@ func LoadAndSendCampaign(cfg *config.AConfig, tmplFile, recipientFile string) er
 	return SendCampaign(cfg, c)
 }
 
-func SendCampaign(cfg *config.AConfig, c *Campaign) error {
+func SendCampaign(cfg *config.AConfig, c *Campaign) (err error) {
 	var s send.Sender
 
+	// Local destinations (e.g. "file://out") are in the project
+	smtp := cfg.SMTP
+	if smtp.Fs == nil && cfg.AppFs != nil {
+		smtp.Fs = cfg.AppFs
+	}
+
 	// Skip dial on dryRun
 	if cfg.DryRun {
 		s = send.NewTestSender()
+	} else if ts, err := send.NewSender(cfg.Context, &smtp); err != nil {
+		return err
 	} else {
-		s = send.NewSMTPSender(cfg.Context, &cfg.SMTP)
+		s = ts
 	}
 
-	q, err := newDefaultQueue(cfg, s, c)
+	// Bcc would be silently dropped
+	if hasBcc(c.headers) && !send.SupportsBcc(s) {
+		return errors.New("Bcc header is not supported by the raw HTTP transport (use \"+json\")")
+	}
+
+	// Hold actual deliveries until scheduled
+	qc := newQueueConfig(cfg, c)
+	if !cfg.DryRun {
+		dc := cfg.Delivery
+		if qc.Schedule, err = send.ParseSchedule(dc.At, dc.Window, dc.Timezone); err != nil {
+			return err
+		}
+	}
+
+	// Audit trail for actual deliveries
+	if path := cfg.Delivery.Ledger; path != "" && !cfg.DryRun {
+		ledger, err := send.OpenLedger(deliveryFs(cfg), path)
+		if err != nil {
+			return fmt.Errorf("failed to open delivery ledger: %w", err)
+		}
+		defer ledger.Close()
+		qc.Reporters = append(qc.Reporters, ledger)
+	}
+
+	// Allow pausing or cancelling via API (see send.FindJob),
+	// and finish the job, unless provided by the caller
+	job, ok := send.CurrentJob(cfg.Context)
+	if !ok {
+		job = send.NewJob(c.ID, c.ListID)
+		fmt.Printf("Starting send job %s\n", job.ID)
+		defer func() { job.Done(err) }()
+	}
+	job.SetTotal(len(c.Recipients))
+	qc.Job = job
+
+	q, err := newDeliveryQueue(cfg, &qc, s, c)
 	if err != nil {
 		return err
 	}
@@ -48,45 +96,86 @@ func SendCampaignDryRun(cfg *config.AConfig, c *Campaign) ([][]byte, error) {
 }
 
 func newDefaultQueue(cfg *config.AConfig, s send.Sender, c *Campaign) (send.Manager, error) {
-	qc := send.Config{SendRate: cfg.SendRate, Workers: cfg.Workers, QueueID: c.ID}
+	qc := newQueueConfig(cfg, c)
 	return send.NewInMemory(cfg.Context, &qc, s)
 }
 
+func newQueueConfig(cfg *config.AConfig, c *Campaign) send.Config {
+	return send.Config{
+		SendRate: cfg.SendRate,
+		Workers:  cfg.Workers,
+		QueueID:  c.ID,
+		Retry:    cfg.Delivery.Retry,
+		Domains:  cfg.Delivery.Domains,
+		Adaptive: cfg.Delivery.Adaptive,
+
+		BatchSize:    cfg.Delivery.BatchSize,
+		SessionLimit: cfg.Delivery.SessionLimit,
+	}
+}
+
+// Delivery queue is configurable, but only list deliveries keep their state
+func newDeliveryQueue(cfg *config.AConfig, qc *send.Config, s send.Sender, c *Campaign) (send.Manager, error) {
+	dc := cfg.Delivery
+	switch dc.Queue {
+	case "", "memory":
+		if dc.Resume {
+			return nil, errors.New("resuming requires the \"disk\" delivery queue")
+		}
+	case "disk":
+		if c.ListID != "" && !cfg.DryRun {
+			qc.StateFile = filepath.Join(dc.QueueDir, c.ID, c.ListID+".jsonl")
+			qc.Resume = dc.Resume
+			return send.NewOnDisk(cfg.Context, qc, s, deliveryFs(cfg))
+		}
+	default:
+		return nil, fmt.Errorf("unknown delivery queue: %s", dc.Queue)
+	}
+
+	return send.NewInMemory(cfg.Context, qc, s)
+}
+
+// Filesystem of queue state and ledger, which is the project, unless overridden
+func deliveryFs(cfg *config.AConfig) afero.Fs {
+	if fs := cfg.Delivery.Fs; fs != nil {
+		return fs
+	}
+	return cfg.AppFs
+}
+
 func sendCampaignTo(cfg *config.AConfig, queue send.Manager, c *Campaign) error {
 	// Capture context cancellation for graceful exit
 	done := cfg.Context.Done()
 	queueErr := make(chan error, 1)
 
-	// Async enqueue for all recipients
+	// Async render and enqueue for all recipients
+	tz := timezones{}
 	go func() {
 		defer close(queueErr)
-		for i := range c.Recipients {
+		defer queue.Close() // Signal that we're done queuing
+
+		err := renderMessages(c, cfg.RenderWorkers, func(i int, m *mail.Msg) error {
 			select {
 			case <-done:
-				queue.Close()
-				queueErr <- errors.New("stopped on context cancellation")
-				return
+				return errors.New("stopped on context cancellation")
 			default:
 			}
 
-			// Render message
-			m := mail.NewMsg(c.MsgOpts...)
-			if err := c.renderMessage(m, i); err != nil {
-				queueErr <- fmt.Errorf("could not render email for recipient %d: %w", i, err)
-				queue.Close()
-				return
-			}
-
 			// Enqueue message directly
-			if err := queue.Enqueue(cfg.Context, m); err != nil {
-				queueErr <- fmt.Errorf("failed to enqueue email: %w", err)
-				queue.Close()
-				return
+			ctx := send.WithRecipientIndex(cfg.Context, c.listPosition(i))
+			if loc := tz.lookup(cfg, c.Recipients[i], i); loc != nil {
+				ctx = send.WithTimezone(ctx, loc)
+			}
+			if err := queue.Enqueue(ctx, m); errors.Is(err, send.ErrJobCancelled) {
+				return err // Reported by Wait
+			} else if err != nil {
+				return fmt.Errorf("failed to enqueue email: %w", err)
 			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, send.ErrJobCancelled) {
+			queueErr <- err
 		}
-
-		// Signal that we're done queuing
-		queue.Close()
 	}()
 
 	// Wait for all tasks to complete
@@ -95,3 +184,26 @@ func sendCampaignTo(cfg *config.AConfig, queue send.Manager, c *Campaign) error
 	// Return queueing/sending errors
 	return errors.Join(errM, <-queueErr)
 }
+
+// Cache of recipient timezones for delivery windows
+type timezones map[string]*time.Location
+
+// lookup loads timezone from recipient's configured column
+func (tz timezones) lookup(cfg *config.AConfig, r *ctxRecipient, i int) *time.Location {
+	col := strings.ToLower(cfg.Delivery.TimezoneColumn)
+	name := strings.TrimSpace(cast.ToString((*r)[col]))
+	if col == "" || name == "" {
+		return nil
+	}
+
+	loc, ok := tz[name]
+	if !ok {
+		var err error
+		if loc, err = time.LoadLocation(name); err != nil {
+			fmt.Printf("Ignoring invalid timezone %q for recipient %d\n", name, i)
+		}
+		tz[name] = loc
+	}
+
+	return loc
+}
@@ -1,3 +1,88 @@
 package mail
 
+import (
+	"strings"
+	"testing"
+
+	"github.com/rykov/paperboy/mail/send"
+	"github.com/spf13/afero"
+)
+
 // Tests for sender functionality have been moved to mail/send/sender_test.go
+
+func TestNewDeliveryQueue(t *testing.T) {
+	cases := []struct {
+		name   string
+		queue  string
+		resume bool
+		dryRun bool
+		list   string
+
+		// Expected outputs
+		stateFile string
+		err       string
+	}{
+		{"Memory queue", "memory", false, false, "list", "", ""},
+		{"Memory queue resume", "memory", true, false, "list", "", "requires the \"disk\""},
+		{"Disk queue", "disk", false, false, "list", ".paperboy/queue/c1/list.jsonl", ""},
+		{"Disk queue resume", "disk", true, false, "sub/list", ".paperboy/queue/c1/sub/list.jsonl", ""},
+		{"Disk queue without list", "disk", false, false, "", "", ""},
+		{"Disk queue on dry run", "disk", false, true, "list", "", ""},
+		{"Unknown queue", "redis", false, false, "list", "", "unknown delivery queue"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := NewTestConfig(t)
+			cfg.Delivery.Queue = c.queue
+			cfg.Delivery.Resume = c.resume
+			cfg.DryRun = c.dryRun
+
+			campaign := &Campaign{ID: "c1", ListID: c.list}
+			qc := newQueueConfig(cfg, campaign)
+			q, err := newDeliveryQueue(cfg, &qc, send.NewTestSender(), campaign)
+			if c.err != "" {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Fatalf("Expected error %q, got %v", c.err, err)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+
+			q.Close()
+			q.Wait()
+
+			if f := c.stateFile; f != "" && !cfg.AppFs.IsFile(f) {
+				t.Errorf("Expected delivery state in %s", f)
+			} else if ok, _ := afero.DirExists(cfg.AppFs, ".paperboy"); f == "" && ok {
+				t.Errorf("Unexpected delivery state")
+			}
+		})
+	}
+}
+
+func TestTimezonesLookup(t *testing.T) {
+	cfg := NewTestConfig(t)
+	cfg.Delivery.TimezoneColumn = "TZ"
+
+	recipients, _ := MapsToRecipients([]map[string]any{
+		{"email": "a@example.com", "tz": "Asia/Tokyo"},
+		{"email": "b@example.com", "tz": "Mars/Olympus"},
+		{"email": "c@example.com"},
+	})
+
+	tz := timezones{}
+	if loc := tz.lookup(cfg, recipients[0], 0); loc == nil || loc.String() != "Asia/Tokyo" {
+		t.Errorf("Unexpected timezone: %v", loc)
+	}
+	if loc := tz.lookup(cfg, recipients[1], 1); loc != nil {
+		t.Errorf("Invalid timezone should be ignored: %v", loc)
+	}
+	if loc := tz.lookup(cfg, recipients[2], 2); loc != nil {
+		t.Errorf("Missing timezone should be ignored: %v", loc)
+	}
+	if len(tz) != 2 {
+		t.Errorf("Expected 2 cached timezones, got %v", tz)
+	}
+}
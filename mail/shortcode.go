@@ -0,0 +1,168 @@
+package mail
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Shortcodes in content, as in {{< button href="/" >}}Sign up{{< /button >}}
+var shortcodeRegexp = regexp.MustCompile(`(?s)\{\{<\s*(/?)\s*([\w./-]+)(.*?)\s*(/?)>\}\}`)
+
+// Content with shortcodes expanded into "shortcode" actions (see
+// templateLibrary), with their inner content as separate templates
+type expandedContent struct {
+	body   []byte
+	inners []shortcodeInner
+	names  []string
+}
+
+// Inner content of a shortcode, and name of its template
+type shortcodeInner struct {
+	name    string
+	content []byte
+}
+
+type shortcodeTag struct {
+	start, end      int
+	name, args      string
+	closing, closed bool
+}
+
+// Expand Hugo-style shortcodes, where a shortcode has inner content
+// when it is closed (e.g. "{{< /note >}}"), unless self-closing
+// (e.g. "{{< note />}}")
+func expandShortcodes(content []byte) (*expandedContent, error) {
+	var tags []shortcodeTag
+	for _, m := range shortcodeRegexp.FindAllSubmatchIndex(content, -1) {
+		tags = append(tags, shortcodeTag{
+			start: m[0], end: m[1],
+			name:    string(content[m[4]:m[5]]),
+			args:    strings.TrimSpace(string(content[m[6]:m[7]])),
+			closing: m[3] > m[2],
+			closed:  m[9] > m[8],
+		})
+	}
+
+	e := &expandedContent{}
+	body, err := e.expand(content, 0, len(content), tags)
+	e.body = body
+	return e, err
+}
+
+func (e *expandedContent) expand(content []byte, lo, hi int, tags []shortcodeTag) ([]byte, error) {
+	var out bytes.Buffer
+	pos := lo
+	for i := 0; i < len(tags); i++ {
+		t := tags[i]
+		out.Write(content[pos:t.start])
+		if t.closing {
+			return nil, fmt.Errorf("unexpected closing shortcode %s", t.name)
+		} else if _, _, err := parseShortcodeArgs(t.args); err != nil {
+			return nil, fmt.Errorf("shortcode %s: %w", t.name, err)
+		}
+
+		inner := ""
+		pos = t.end
+		if j := closingShortcode(tags, i); j > 0 {
+			innerBody, err := e.expand(content, t.end, tags[j].start, tags[i+1:j])
+			if err != nil {
+				return nil, err
+			}
+			inner = fmt.Sprintf("shortcode-%d", len(e.inners)+1)
+			e.inners = append(e.inners, shortcodeInner{name: inner, content: innerBody})
+			pos, i = tags[j].end, j
+		}
+
+		if !slices.Contains(e.names, t.name) {
+			e.names = append(e.names, t.name)
+		}
+		fmt.Fprintf(&out, "{{ shortcode %s %s %s $ }}", templateQuote(t.name), templateQuote(inner), templateQuote(t.args))
+	}
+	out.Write(content[pos:hi])
+	return out.Bytes(), nil
+}
+
+// Index of the tag closing tags[i], or -1
+func closingShortcode(tags []shortcodeTag, i int) int {
+	if tags[i].closed {
+		return -1
+	}
+
+	depth := 0
+	for k := i + 1; k < len(tags); k++ {
+		switch t := tags[k]; {
+		case t.name != tags[i].name:
+		case t.closing && depth == 0:
+			return k
+		case t.closing:
+			depth--
+		case !t.closed:
+			depth++
+		}
+	}
+	return -1
+}
+
+// Parse named (e.g. href="/") and positional arguments of shortcodes,
+// which are either "quoted", `raw` or bare words
+func parseShortcodeArgs(s string) (named map[string]string, positional []string, err error) {
+	named = map[string]string{}
+	for s = strings.TrimSpace(s); s != ""; s = strings.TrimSpace(s) {
+		var key, value string
+		if s[0] != '"' && s[0] != '`' {
+			end := strings.IndexAny(s, "= \t\r\n")
+			if end < 0 {
+				end = len(s)
+			}
+			key, s = s[:end], s[end:]
+			if !strings.HasPrefix(s, "=") {
+				positional = append(positional, key)
+				continue
+			}
+			s = s[1:]
+		}
+
+		if value, s, err = shortcodeValue(s); err != nil {
+			return nil, nil, err
+		} else if key == "" {
+			positional = append(positional, value)
+		} else {
+			named[key] = value
+		}
+	}
+	return named, positional, nil
+}
+
+// Quoted or bare value, and the rest of arguments
+func shortcodeValue(s string) (value, rest string, err error) {
+	switch {
+	case strings.HasPrefix(s, "`"):
+		end := strings.IndexByte(s[1:], '`')
+		if end < 0 {
+			return "", "", fmt.Errorf("unterminated raw string: %s", s)
+		}
+		return s[1 : end+1], s[end+2:], nil
+	case strings.HasPrefix(s, `"`):
+		q, err := strconv.QuotedPrefix(s)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid string: %s", s)
+		}
+		value, _ = strconv.Unquote(q)
+		return value, s[len(q):], nil
+	}
+
+	end := strings.IndexAny(s, " \t\r\n")
+	if end < 0 {
+		end = len(s)
+	}
+	return s[:end], s[end:], nil
+}
+
+// Quote string for templates, so that braces do not end actions
+func templateQuote(s string) string {
+	return strings.NewReplacer("{", `\x7b`, "}", `\x7d`).Replace(strconv.Quote(s))
+}
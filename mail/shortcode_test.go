@@ -0,0 +1,62 @@
+package mail
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestExpandShortcodes(t *testing.T) {
+	content := `Hi {{< name >}},
+{{< button href="https://example.com/{x}" >}}Sign **up**{{< /button >}}
+{{< note />}} {{< box >}}a {{< box >}}b{{< /box >}}{{< /box >}}`
+
+	e, err := expandShortcodes([]byte(content))
+	if err != nil {
+		t.Fatalf("expandShortcodes() failed: %s", err)
+	}
+
+	expected := `Hi {{ shortcode "name" "" "" $ }},
+{{ shortcode "button" "shortcode-1" "href=\"https://example.com/\x7bx\x7d\"" $ }}
+{{ shortcode "note" "" "" $ }} {{ shortcode "box" "shortcode-3" "" $ }}`
+	if d := cmp.Diff(expected, string(e.body)); d != "" {
+		t.Errorf("Unexpected body: %s", d)
+	}
+
+	inners := map[string]string{}
+	for _, in := range e.inners {
+		inners[in.name] = string(in.content)
+	}
+	expectedInners := map[string]string{
+		"shortcode-1": "Sign **up**",
+		"shortcode-2": "b",
+		"shortcode-3": `a {{ shortcode "box" "shortcode-2" "" $ }}`,
+	}
+	if d := cmp.Diff(expectedInners, inners); d != "" {
+		t.Errorf("Unexpected inner content: %s", d)
+	}
+	if d := cmp.Diff([]string{"name", "button", "note", "box"}, e.names); d != "" {
+		t.Errorf("Unexpected names: %s", d)
+	}
+
+	for _, bad := range []string{"{{< /button >}}", `{{< button href="x >}}`} {
+		if _, err := expandShortcodes([]byte(bad)); err == nil {
+			t.Errorf("Expected error for %q", bad)
+		}
+	}
+}
+
+func TestParseShortcodeArgs(t *testing.T) {
+	named, positional, err := parseShortcodeArgs("first href=\"a \\\"b\\\"\" raw=`c d` bare=e/f/ \"second\"")
+	if err != nil {
+		t.Fatalf("parseShortcodeArgs() failed: %s", err)
+	}
+
+	expected := map[string]string{"href": `a "b"`, "raw": "c d", "bare": "e/f/"}
+	if d := cmp.Diff(expected, named); d != "" {
+		t.Errorf("Unexpected named arguments: %s", d)
+	}
+	if d := cmp.Diff([]string{"first", "second"}, positional); d != "" {
+		t.Errorf("Unexpected positional arguments: %s", d)
+	}
+}
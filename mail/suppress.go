@@ -0,0 +1,283 @@
+package mail
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rykov/paperboy/config"
+	"github.com/spf13/afero"
+)
+
+// Reasons for suppressing an address
+const (
+	SuppressUnsubscribed = "unsubscribed"
+	SuppressBounced      = "bounced"
+	SuppressComplained   = "complained"
+	SuppressManual       = "manual"
+)
+
+// Column order of the suppression list
+var suppressionHeader = []string{"email", "reason", "time", "campaign"}
+
+// Serializes updates of suppression lists within the process
+var suppressionLock sync.Mutex
+
+// Suppression excludes an address from all sends
+type Suppression struct {
+	Email  string
+	Reason string
+	Time   time.Time
+
+	// Campaign of the unsubscribe or bounce, if known
+	Campaign string
+}
+
+// Suppressions is the project's list of suppressed addresses
+type Suppressions struct {
+	entries []*Suppression
+	index   map[string]*Suppression
+}
+
+// LoadSuppressions from the project (see config.Fs.SuppressionPath),
+// which is empty when the list does not exist yet
+func LoadSuppressions(cfg *config.AConfig) (*Suppressions, error) {
+	s := &Suppressions{index: map[string]*Suppression{}}
+	path := cfg.AppFs.SuppressionPath()
+
+	file, err := cfg.AppFs.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if err := s.read(file, ""); err != nil {
+		return nil, fmt.Errorf("failed to load suppression list %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// UpdateSuppressions loads, modifies and saves the project's suppressions
+func UpdateSuppressions(cfg *config.AConfig, fn func(*Suppressions) error) error {
+	suppressionLock.Lock()
+	defer suppressionLock.Unlock()
+
+	s, err := LoadSuppressions(cfg)
+	if err != nil {
+		return err
+	} else if err := fn(s); err != nil {
+		return err
+	}
+	return s.save(cfg.AppFs, cfg.AppFs.SuppressionPath())
+}
+
+// Suppress addresses for reason, keeping earlier suppressions
+func Suppress(cfg *config.AConfig, reason string, emails ...string) (added int, err error) {
+	err = UpdateSuppressions(cfg, func(s *Suppressions) error {
+		now := time.Now()
+		for _, e := range emails {
+			if s.Add(e, reason, now) {
+				added++
+			}
+		}
+		return nil
+	})
+	return added, err
+}
+
+// Add address to the list, unless it is already suppressed
+func (s *Suppressions) Add(email, reason string, t time.Time) bool {
+	return s.Insert(Suppression{Email: email, Reason: reason, Time: t})
+}
+
+// Insert suppression, unless its address is already suppressed
+func (s *Suppressions) Insert(e Suppression) bool {
+	key := suppressionKey(e.Email)
+	if _, ok := s.index[key]; ok || key == "" {
+		return false
+	}
+
+	e.Email, e.Time = strings.TrimSpace(e.Email), e.Time.UTC()
+	s.entries = append(s.entries, &e)
+	s.index[key] = &e
+	return true
+}
+
+// Remove address from the list
+func (s *Suppressions) Remove(email string) bool {
+	key := suppressionKey(email)
+	e, ok := s.index[key]
+	if ok {
+		delete(s.index, key)
+		s.entries = slices.DeleteFunc(s.entries, func(o *Suppression) bool { return o == e })
+	}
+	return ok
+}
+
+// Find suppression of an address (case-insensitive)
+func (s *Suppressions) Find(email string) (*Suppression, bool) {
+	e, ok := s.index[suppressionKey(email)]
+	return e, ok
+}
+
+// All suppressions in the order they were added
+func (s *Suppressions) All() []Suppression {
+	out := make([]Suppression, len(s.entries))
+	for i, e := range s.entries {
+		out[i] = *e
+	}
+	return out
+}
+
+func (s *Suppressions) Len() int {
+	return len(s.entries)
+}
+
+// Import suppressions from CSV with an "email" column, and optional
+// "reason", "time" and "campaign" columns, using reason where it is missing
+func (s *Suppressions) Import(r io.Reader, reason string) (int, error) {
+	before := s.Len()
+	err := s.read(r, reason)
+	return s.Len() - before, err
+}
+
+func (s *Suppressions) read(r io.Reader, reason string) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	// Locate known columns
+	cols := map[string]int{"email": -1, "reason": -1, "time": -1, "campaign": -1}
+	for i, h := range header {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if _, ok := cols[h]; ok && cols[h] < 0 {
+			cols[h] = i
+		}
+	}
+	if cols["email"] < 0 {
+		return errors.New("no \"email\" column")
+	}
+
+	field := func(rec []string, col string) string {
+		if i := cols[col]; i >= 0 && i < len(rec) {
+			return strings.TrimSpace(rec[i])
+		}
+		return ""
+	}
+
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		e := Suppression{
+			Email:    field(rec, "email"),
+			Reason:   field(rec, "reason"),
+			Campaign: field(rec, "campaign"),
+		}
+		if e.Reason == "" {
+			e.Reason = reason
+		}
+		if t := field(rec, "time"); t != "" {
+			if e.Time, err = time.Parse(time.RFC3339, t); err != nil {
+				return fmt.Errorf("invalid time for %s: %w", e.Email, err)
+			}
+		}
+		s.Insert(e)
+	}
+}
+
+// Write the list atomically, to not lose it on a crash
+func (s *Suppressions) save(fs afero.Fs, path string) error {
+	if err := fs.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	file, err := fs.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	w := csv.NewWriter(file)
+	w.Write(suppressionHeader)
+	for _, e := range s.entries {
+		var t string
+		if !e.Time.IsZero() {
+			t = e.Time.Format(time.RFC3339)
+		}
+		w.Write([]string{e.Email, e.Reason, t, e.Campaign})
+	}
+	w.Flush()
+
+	if err := errors.Join(w.Error(), file.Close()); err != nil {
+		fs.Remove(tmp)
+		return err
+	}
+	return fs.Rename(tmp, path)
+}
+
+// Addresses are compared case-insensitively
+func suppressionKey(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// Suppress removes suppressed recipients from the campaign,
+// and returns how many were removed
+func (c *Campaign) Suppress(s *Suppressions) int {
+	if s.Len() == 0 {
+		return 0
+	}
+
+	kept, positions := c.Recipients[:0:0], []int{}
+	for i, r := range c.Recipients {
+		if _, ok := s.Find(r.Email()); !ok {
+			kept = append(kept, r)
+			positions = append(positions, c.listPosition(i))
+		}
+	}
+
+	removed := len(c.Recipients) - len(kept)
+	c.Recipients, c.positions = kept, positions
+	return removed
+}
+
+// Position of recipient i in the original list, which identifies
+// recipients for resumed deliveries (see send.WithRecipientIndex)
+func (c *Campaign) listPosition(i int) int {
+	if c.positions != nil {
+		return c.positions[i]
+	}
+	return i
+}
+
+// ExcludeSuppressed removes recipients in cfg project's suppression list
+func (c *Campaign) ExcludeSuppressed(cfg *config.AConfig) error {
+	s, err := LoadSuppressions(cfg)
+	if err != nil {
+		return err
+	}
+	if n := c.Suppress(s); n > 0 {
+		fmt.Printf("Skipping %d suppressed recipients\n", n)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package mail
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/rykov/paperboy/config"
+	"github.com/spf13/afero"
+)
+
+func TestSuppressions(t *testing.T) {
+	cfg, _ := config.LoadConfigFs(t.Context(), afero.NewMemMapFs())
+
+	// Missing list is empty
+	if s, err := LoadSuppressions(cfg); err != nil || s.Len() != 0 {
+		t.Fatalf("Expected empty list, got %+v (%v)", s, err)
+	}
+
+	// Addresses are unique regardless of case
+	added, err := Suppress(cfg, SuppressUnsubscribed, "Jane@Example.com", "john@example.com", "jane@example.com ")
+	if err != nil || added != 2 {
+		t.Fatalf("Expected 2 added, got %d (%v)", added, err)
+	}
+
+	err = UpdateSuppressions(cfg, func(s *Suppressions) error {
+		if !s.Remove("JOHN@example.com") || s.Remove("nobody@example.com") {
+			t.Errorf("Unexpected removal results")
+		}
+		s.Add("bob@example.com", SuppressBounced, time.Time{})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Reload from disk
+	s, err := LoadSuppressions(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	emails := []string{}
+	for _, e := range s.All() {
+		emails = append(emails, e.Email+":"+e.Reason)
+	}
+	if exp := []string{"Jane@Example.com:unsubscribed", "bob@example.com:bounced"}; !cmp.Equal(emails, exp) {
+		t.Errorf("Unexpected suppressions %v, expected %v", emails, exp)
+	}
+	if e, ok := s.Find("jane@example.com"); !ok || e.Time.IsZero() {
+		t.Errorf("Expected timestamped suppression, got %+v", e)
+	}
+}
+
+func TestSuppressionsImport(t *testing.T) {
+	s := &Suppressions{index: map[string]*Suppression{}}
+	s.Add("a@example.com", SuppressManual, time.Time{})
+
+	csv := "Reason,Email,Name,Time\n" +
+		"complained,b@example.com,Bob,2025-03-01T09:00:00Z\n" +
+		",c@example.com,Carol,\n" +
+		"bounced,A@example.com,Alice,\n"
+	added, err := s.Import(strings.NewReader(csv), SuppressBounced)
+	if err != nil || added != 2 {
+		t.Fatalf("Expected 2 imported, got %d (%v)", added, err)
+	}
+
+	if e, _ := s.Find("b@example.com"); e == nil || e.Reason != SuppressComplained || e.Time.Year() != 2025 {
+		t.Errorf("Unexpected import %+v", e)
+	}
+	if e, _ := s.Find("c@example.com"); e == nil || e.Reason != SuppressBounced {
+		t.Errorf("Expected default reason, got %+v", e)
+	}
+
+	// Email column is required
+	if _, err := s.Import(strings.NewReader("address\nd@example.com\n"), ""); err == nil {
+		t.Errorf("Expected error for missing email column")
+	}
+}
+
+func TestLoadCampaignExcludesSuppressed(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	afero.WriteFile(fs, "content/c1.md", []byte("# Hello"), 0644)
+	afero.WriteFile(fs, "lists/r1.yaml", []byte(`
+- email: a@example.com
+- email: B@example.com
+- email: c@example.com
+- email: d@example.com
+`), 0644)
+	afero.WriteFile(fs, "lists/_suppressed.csv", []byte("email,reason,time\nb@example.com,unsubscribed,\nd@example.com,bounced,\n"), 0644)
+
+	cfg, _ := config.LoadConfigFs(t.Context(), fs)
+	c, err := LoadCampaign(cfg, "c1", "r1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	emails := []string{}
+	for _, r := range c.Recipients {
+		emails = append(emails, r.Email())
+	}
+	if exp := []string{"a@example.com", "c@example.com"}; !cmp.Equal(emails, exp) {
+		t.Errorf("Expected recipients %v, got %v", exp, emails)
+	}
+
+	// Positions in the list are kept for resumed deliveries
+	if p := []int{c.listPosition(0), c.listPosition(1)}; !cmp.Equal(p, []int{0, 2}) {
+		t.Errorf("Unexpected list positions %v", p)
+	}
+
+	// Further suppression keeps original positions
+	s := &Suppressions{index: map[string]*Suppression{}}
+	s.Add("a@example.com", SuppressManual, time.Time{})
+	if n := c.Suppress(s); n != 1 || len(c.Recipients) != 1 || c.listPosition(0) != 2 {
+		t.Errorf("Unexpected suppression of %d: %v", n, c.positions)
+	}
+}
@@ -0,0 +1,187 @@
+package mail
+
+import (
+	"bytes"
+	"fmt"
+	html "html/template"
+	"io"
+	"maps"
+	"path/filepath"
+	"sync"
+	"text/template"
+
+	"github.com/rykov/paperboy/config"
+)
+
+// Template with associated templates (e.g. inner content of shortcodes)
+type templateSet interface {
+	Template
+	ExecuteTemplate(wr io.Writer, name string, data any) error
+}
+
+// Layouts, partials and shortcodes of a campaign for HTML or plain
+// text, which are parsed once, with shared functions (templateFuncs)
+type templateLibrary struct {
+	fs   *config.Fs
+	html bool
+
+	lock  sync.Mutex
+	cache map[string]templateSet
+}
+
+func newTemplateLibrary(fs *config.Fs, html bool) *templateLibrary {
+	return &templateLibrary{fs: fs, html: html, cache: map[string]templateSet{}}
+}
+
+// Parse template with shared functions, "partial" and "shortcode"
+func (l *templateLibrary) parse(name, text string) (templateSet, error) {
+	var self templateSet
+	funcs := maps.Clone(templateFuncs)
+	funcs["partial"] = l.partial
+	funcs["shortcode"] = func(name, inner, args string, ctx *tmplContext) (any, error) {
+		return l.shortcode(self, name, inner, args, ctx)
+	}
+
+	if l.html {
+		t, err := html.New(name).Funcs(html.FuncMap(funcs)).Parse(text)
+		if err != nil {
+			return nil, err
+		}
+		self = t
+	} else {
+		t, err := template.New(name).Funcs(funcs).Parse(text)
+		if err != nil {
+			return nil, err
+		}
+		self = t
+	}
+	return self, nil
+}
+
+// Load template at path, or def without such file
+func (l *templateLibrary) load(path, def string) (templateSet, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	key := path + "\x00" + def
+	if t, ok := l.cache[key]; ok {
+		return t, nil
+	}
+
+	text, err := loadTemplate(l.fs, path, def)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := l.parse(filepath.Base(path), text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	l.cache[key] = t
+	return t, nil
+}
+
+// Find partial or shortcode by name, with or without extension (e.g.
+// "footer" or "footer.html"), where plain text prefers ".text" files
+func (l *templateLibrary) find(dir, name string) string {
+	exts := []string{".html"}
+	if !l.html {
+		exts = []string{".text", ".html"}
+	}
+	if filepath.Ext(name) != "" {
+		exts = []string{""}
+	}
+
+	for _, ext := range exts {
+		if p := l.fs.LayoutPath(filepath.Join(dir, name+ext)); p != "" {
+			return p
+		}
+	}
+	return ""
+}
+
+// Render partial, as in {{ partial "footer" . }}
+func (l *templateLibrary) partial(name string, data ...any) (any, error) {
+	path := l.find("partials", name)
+	if path == "" {
+		return nil, fmt.Errorf("partial %q not found", name)
+	}
+
+	t, err := l.load(path, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var ctx any
+	if len(data) > 0 {
+		ctx = data[0]
+	}
+	return l.execute(t, ctx)
+}
+
+// Render shortcode of content (see expandShortcodes) with its arguments,
+// and inner content from its template in self
+func (l *templateLibrary) shortcode(self templateSet, name, inner, args string, ctx *tmplContext) (any, error) {
+	path := l.find("shortcodes", name)
+	if path == "" {
+		return nil, fmt.Errorf("shortcode %q not found", name)
+	}
+
+	t, err := l.load(path, "")
+	if err != nil {
+		return nil, err
+	}
+
+	sc := &shortcodeContext{tmplContext: ctx, Name: name}
+	if sc.Params, sc.positional, err = parseShortcodeArgs(args); err != nil {
+		return nil, err
+	}
+
+	if inner != "" {
+		var buf bytes.Buffer
+		if err := self.ExecuteTemplate(&buf, inner, ctx); err != nil {
+			return nil, err
+		}
+		sc.Inner = l.output(buf.String())
+	}
+	return l.execute(t, sc)
+}
+
+func (l *templateLibrary) execute(t Template, data any) (any, error) {
+	var out bytes.Buffer
+	if err := t.Execute(&out, data); err != nil {
+		return nil, err
+	}
+	return l.output(out.String()), nil
+}
+
+// Rendered HTML is not escaped again
+func (l *templateLibrary) output(s string) any {
+	if l.html {
+		return html.HTML(s)
+	}
+	return s
+}
+
+// Context of shortcode templates, with message's context
+type shortcodeContext struct {
+	*tmplContext
+	Name   string
+	Params map[string]string
+	Inner  any
+
+	positional []string
+}
+
+// Get named (e.g. "href") or positional (e.g. 0) argument
+func (s *shortcodeContext) Get(key any) string {
+	switch k := key.(type) {
+	case string:
+		return s.Params[k]
+	case int:
+		if k >= 0 && k < len(s.positional) {
+			return s.positional[k]
+		}
+	}
+	return ""
+}
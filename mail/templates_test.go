@@ -0,0 +1,107 @@
+package mail
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/rykov/paperboy/config"
+	"github.com/spf13/afero"
+)
+
+func TestPartialsAndShortcodes(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	afero.WriteFile(memFs, "content/c1.md", []byte(`---
+subject: "{{ .Recipient.name | upper }}, hello"
+---
+{{ partial "greeting" . }}
+
+{{< button href="https://example.com/?n=1&m=2" >}}Sign **up**{{< /button >}}
+`), 0644)
+
+	// Partials of layouts, with a theme's fallback
+	afero.WriteFile(memFs, "layouts/partials/greeting.html", []byte(`Hi <b>{{ .Recipient.name | default "friend" }}</b>`), 0644)
+	afero.WriteFile(memFs, "layouts/partials/greeting.text", []byte(`Hi {{ .Recipient.name | upper }}`), 0644)
+	afero.WriteFile(memFs, "themes/t1/layouts/partials/footer.html", []byte(`<footer>{{ .Address }}</footer>`), 0644)
+	afero.WriteFile(memFs, "layouts/shortcodes/button.html", []byte(`<a class="button" href="{{ .Get "href" }}">{{ .Inner }}</a>`), 0644)
+	afero.WriteFile(memFs, "layouts/_default.html", []byte(`<html><body>{{ .Content }}{{ partial "footer" . }}</body></html>`), 0644)
+	afero.WriteFile(memFs, "layouts/_default.text", []byte(`{{ .Content }}-- {{ partial "footer.html" . }}`), 0644)
+
+	cfg, _ := config.LoadConfigFs(t.Context(), memFs)
+	cfg.From = "news@example.com"
+	cfg.Theme = "t1"
+	cfg.Address = "1 Main St & Co"
+
+	c, err := LoadContent(cfg, "c1")
+	if err != nil {
+		t.Fatalf("LoadContent() failed: %s", err)
+	}
+	c.Recipients, _ = MapsToRecipients([]map[string]any{
+		{"email": "jane@example.com", "name": "<jane>"},
+	})
+
+	ctx, _ := c.templateContextFor(0)
+	var htmlBody, textBody bytes.Buffer
+	if err := c.htmlBodyTemplate.Execute(&htmlBody, ctx); err != nil {
+		t.Fatalf("HTML body failed: %s", err)
+	} else if err := c.bodyTemplate.Execute(&textBody, ctx); err != nil {
+		t.Fatalf("Text body failed: %s", err)
+	}
+
+	for _, e := range []string{
+		"Hi <b>&lt;jane&gt;</b>",
+		`<a class="button" href="https://example.com/?n=1&amp;m=2">Sign <strong>up</strong></a>`,
+	} {
+		if !strings.Contains(htmlBody.String(), e) {
+			t.Errorf("Expected %q in HTML body:\n%s", e, htmlBody.String())
+		}
+	}
+	if e := "Hi <JANE>"; !strings.Contains(textBody.String(), e) {
+		t.Errorf("Expected %q in text body:\n%s", e, textBody.String())
+	}
+
+	// Layouts with theme's partial
+	html, err := c.renderHTML(htmlBody.Bytes(), ctx)
+	if err != nil {
+		t.Fatalf("renderHTML() failed: %s", err)
+	} else if e := "<footer>1 Main St &amp; Co</footer>"; !strings.Contains(html, e) {
+		t.Errorf("Expected %q in HTML:\n%s", e, html)
+	}
+
+	text, err := c.renderPlain(textBody.Bytes(), ctx)
+	if err != nil {
+		t.Fatalf("renderPlain() failed: %s", err)
+	} else if e := "-- <footer>1 Main St & Co</footer>"; !strings.Contains(text, e) {
+		t.Errorf("Expected %q in text:\n%s", e, text)
+	}
+
+	// Functions in subject
+	if _, err := c.MessageFor(0); err != nil {
+		t.Fatalf("MessageFor() failed: %s", err)
+	}
+	subject, _ := executeTemplate(nil, c.subjectTemplate, ctx)
+	if subject != "<JANE>, hello" {
+		t.Errorf("Unexpected subject: %q", subject)
+	}
+}
+
+func TestMissingPartialOrShortcode(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	afero.WriteFile(memFs, "content/shortcode.md", []byte(`Hi {{< missing >}}`), 0644)
+	afero.WriteFile(memFs, "content/partial.md", []byte(`Hi {{ partial "missing" . }}`), 0644)
+
+	cfg, _ := config.LoadConfigFs(t.Context(), memFs)
+	cfg.From = "news@example.com"
+	if _, err := LoadContent(cfg, "shortcode"); err == nil || !strings.Contains(err.Error(), `shortcode "missing" not found`) {
+		t.Errorf("Expected missing shortcode error, got %v", err)
+	}
+
+	c, err := LoadContent(cfg, "partial")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Recipients, _ = MapsToRecipients([]map[string]any{{"email": "jane@example.com"}})
+	if _, err := c.MessageFor(0); err == nil || !strings.Contains(err.Error(), `partial "missing" not found`) {
+		t.Errorf("Expected missing partial error, got %v", err)
+	}
+}
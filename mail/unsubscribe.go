@@ -0,0 +1,49 @@
+package mail
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// Length of truncated HMAC-SHA256 in unsubscribe tokens
+const unsubscribeMACSize = 16
+
+var errInvalidToken = errors.New("invalid unsubscribe token")
+
+// UnsubscribeToken signs recipient's email and campaign ID with secret,
+// for "{token}" variable of the unsubscribeURL config template
+func UnsubscribeToken(secret, campaign, email string) string {
+	payload := []byte(campaign + "\x00" + email)
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(payload) + "." + enc.EncodeToString(unsubscribeMAC(secret, payload))
+}
+
+// ParseUnsubscribeToken verifies the token, and returns its campaign ID and email
+func ParseUnsubscribeToken(secret, token string) (campaign, email string, err error) {
+	enc := base64.RawURLEncoding
+	p64, m64, ok := strings.Cut(token, ".")
+	if !ok || secret == "" {
+		return "", "", errInvalidToken
+	}
+
+	payload, err1 := enc.DecodeString(p64)
+	mac, err2 := enc.DecodeString(m64)
+	if err1 != nil || err2 != nil || !hmac.Equal(mac, unsubscribeMAC(secret, payload)) {
+		return "", "", errInvalidToken
+	}
+
+	campaign, email, ok = strings.Cut(string(payload), "\x00")
+	if !ok || email == "" {
+		return "", "", errInvalidToken
+	}
+	return campaign, email, nil
+}
+
+func unsubscribeMAC(secret string, payload []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write(payload)
+	return h.Sum(nil)[:unsubscribeMACSize]
+}
@@ -0,0 +1,100 @@
+package mail
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/rykov/paperboy/config"
+	"github.com/spf13/afero"
+	"github.com/wneessen/go-mail"
+)
+
+func TestUnsubscribeToken(t *testing.T) {
+	token := UnsubscribeToken("s3cret", "newsletter", "jane@example.com")
+	if c, e, err := ParseUnsubscribeToken("s3cret", token); err != nil || c != "newsletter" || e != "jane@example.com" {
+		t.Fatalf("Unexpected parse %q, %q (%v)", c, e, err)
+	}
+
+	// Token is safe in a URL path
+	if url.PathEscape(token) != token {
+		t.Errorf("Token needs escaping: %s", token)
+	}
+
+	forged := UnsubscribeToken("other", "newsletter", "john@example.com")
+	payload, mac, _ := strings.Cut(token, ".")
+	forgedPayload, _, _ := strings.Cut(forged, ".")
+
+	for _, bad := range []string{"", "abc", token + "x", forged, forgedPayload + "." + mac, payload + ".", "." + mac} {
+		if _, _, err := ParseUnsubscribeToken("s3cret", bad); err == nil {
+			t.Errorf("Expected invalid token %q", bad)
+		}
+	}
+
+	// Disabled without secret
+	if _, _, err := ParseUnsubscribeToken("", UnsubscribeToken("", "c", "e@example.com")); err == nil {
+		t.Errorf("Expected error without secret")
+	}
+}
+
+func TestUnsubscribeURLToken(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	afero.WriteFile(fs, "content/c1.md", []byte("# Hello"), 0644)
+	afero.WriteFile(fs, "lists/r1.yaml", []byte("- email: jane@example.com\n"), 0644)
+
+	cfg, _ := config.LoadConfigFs(t.Context(), fs)
+	cfg.UnsubscribeURL = "https://example.com/unsubscribe/{token}"
+	cfg.UnsubscribeSecret = "s3cret"
+
+	c, err := LoadCampaign(cfg, "c1", "r1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, err := c.templateContextFor(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "https://example.com/unsubscribe/" + UnsubscribeToken("s3cret", "c1", "jane@example.com")
+	if u := ctx.UnsubscribeURL; u != expected {
+		t.Errorf("Expected %s, got %s", expected, u)
+	}
+}
+
+func TestListUnsubscribeHeaders(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	afero.WriteFile(fs, "content/c1.md", []byte("# Hello"), 0644)
+	afero.WriteFile(fs, "lists/r1.yaml", []byte("- email: jane@example.com\n"), 0644)
+
+	cases := []struct {
+		url, mailto  string
+		header, post string
+	}{
+		{"", "", "", ""},
+		{"https://example.com/u/{Recipient.email}", "", "<https://example.com/u/jane%40example.com>", "List-Unsubscribe=One-Click"},
+		{"https://example.com/u/{Recipient.email}", "unsubscribe@example.com?subject={Recipient.email}",
+			"<mailto:unsubscribe@example.com?subject=jane%40example.com>, <https://example.com/u/jane%40example.com>", "List-Unsubscribe=One-Click"},
+		{"", "mailto:unsubscribe@example.com", "<mailto:unsubscribe@example.com>", ""},
+	}
+
+	for _, tc := range cases {
+		cfg, _ := config.LoadConfigFs(t.Context(), fs)
+		cfg.From = "sender@example.com"
+		cfg.UnsubscribeURL, cfg.UnsubscribeMailto = tc.url, tc.mailto
+
+		c, err := LoadCampaign(cfg, "c1", "r1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, err := c.MessageFor(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		header := strings.Join(m.GetGenHeader(mail.HeaderListUnsubscribe), ", ")
+		post := strings.Join(m.GetGenHeader(mail.HeaderListUnsubscribePost), ", ")
+		if header != tc.header || post != tc.post {
+			t.Errorf("%q/%q: unexpected headers %q, %q", tc.url, tc.mailto, header, post)
+		}
+	}
+}
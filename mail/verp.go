@@ -0,0 +1,39 @@
+package mail
+
+import (
+	"strings"
+)
+
+// verp encodes an address into the local part of another
+// (e.g. "jane@example.com" as "jane=example.com"), per VERP
+func verp(email string) string {
+	local, domain, ok := strings.Cut(strings.TrimSpace(email), "@")
+	if !ok {
+		return local
+	}
+	return local + "=" + domain
+}
+
+// ParseVERP decodes recipient's address from a VERP address, where the
+// local part ends with its encoding after the first "+" (e.g. as in
+// "bounces+jane=example.com@example.org")
+func ParseVERP(address string) (string, bool) {
+	address = strings.Trim(strings.TrimSpace(address), "<>")
+	at := strings.LastIndex(address, "@")
+	if at < 0 {
+		return "", false
+	}
+
+	local := address[:at]
+	sep := strings.IndexByte(local, '+')
+	if sep < 0 {
+		return "", false
+	}
+
+	encoded := local[sep+1:]
+	eq := strings.LastIndex(encoded, "=")
+	if eq <= 0 || eq == len(encoded)-1 {
+		return "", false
+	}
+	return encoded[:eq] + "@" + encoded[eq+1:], true
+}
@@ -0,0 +1,71 @@
+package mail
+
+import (
+	"testing"
+
+	"github.com/rykov/paperboy/config"
+	"github.com/spf13/afero"
+)
+
+func TestVERP(t *testing.T) {
+	for _, email := range []string{"jane@example.com", "mary-jane+news@example.co.uk"} {
+		encoded := "bounces+" + verp(email) + "@example.org"
+		if decoded, ok := ParseVERP("<" + encoded + ">"); !ok || decoded != email {
+			t.Errorf("Roundtrip of %s via %s failed: %q", email, encoded, decoded)
+		}
+	}
+
+	for _, bad := range []string{"", "bounces@example.org", "bounces+jane@example.org", "bounces+=example.com@example.org", "bounces+jane=@example.org"} {
+		if decoded, ok := ParseVERP(bad); ok {
+			t.Errorf("Expected %q not to decode, got %q", bad, decoded)
+		}
+	}
+}
+
+func TestEnvelopeFrom(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	afero.WriteFile(fs, "content/default.md", []byte("# Hello"), 0644)
+	afero.WriteFile(fs, "content/custom.md", []byte("---\nenvelopeFrom: \"list-{{ .Campaign.Params.id }}@example.org\"\nid: 42\n---\n# Hello"), 0644)
+	afero.WriteFile(fs, "lists/r1.yaml", []byte("- email: jane@example.com\n"), 0644)
+
+	cases := []struct {
+		config, content string
+		sender          string
+	}{
+		{"", "default", "sender@example.com"},
+		{"bounces+{{ .Recipient.Email | verp }}@example.org", "default", "bounces+jane=example.com@example.org"},
+		{"bounces+{{ .Recipient.Email | verp }}@example.org", "custom", "list-42@example.org"},
+	}
+
+	for _, tc := range cases {
+		cfg, _ := config.LoadConfigFs(t.Context(), fs)
+		cfg.From = "Sender <sender@example.com>"
+		cfg.EnvelopeFrom = tc.config
+
+		c, err := LoadCampaign(cfg, tc.content, "r1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, err := c.MessageFor(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if s, _ := m.GetSender(false); s != "<"+tc.sender+">" {
+			t.Errorf("%q/%s: expected sender %s, got %s", tc.config, tc.content, tc.sender, s)
+		}
+		if f := m.GetFromString(); len(f) != 1 || f[0] != `"Sender" <sender@example.com>` {
+			t.Errorf("%q/%s: unexpected From %v", tc.config, tc.content, f)
+		}
+		if _, ok := c.EmailMeta.Params["envelopefrom"]; ok {
+			t.Errorf("envelopeFrom should not be in campaign params")
+		}
+	}
+
+	// Invalid template fails loading
+	cfg, _ := config.LoadConfigFs(t.Context(), fs)
+	cfg.EnvelopeFrom = "{{ .Broken"
+	if _, err := LoadContent(cfg, "default"); err == nil {
+		t.Errorf("Expected envelopeFrom parse error")
+	}
+}
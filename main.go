@@ -34,7 +34,7 @@ var (
 
 // Commands managed by Cobra
 func main() {
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	ctx, stop := signalContext()
 	defer stop() // unregistered signal handlers
 
 	bi := config.BuildInfo{
@@ -51,3 +51,9 @@ func main() {
 		}
 	}
 }
+
+// Context of commands, which is cancelled on interrupt (Ctrl-C)
+// or termination, and which local sends cancel their job on
+func signalContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
@@ -0,0 +1,73 @@
+//go:build unix
+
+package main
+
+import (
+	"github.com/rykov/paperboy/cmd"
+	"github.com/rykov/paperboy/config"
+	"github.com/rykov/paperboy/mail/send"
+
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestInterruptLocalSend(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	var list strings.Builder
+	for i := range 50 {
+		fmt.Fprintf(&list, "- email: user%d@example.com\n", i)
+	}
+	os.MkdirAll("content", 0755)
+	os.MkdirAll("lists", 0755)
+	os.WriteFile("content/c1.md", []byte("# Hello"), 0644)
+	os.WriteFile("lists/all.yaml", []byte(list.String()), 0644)
+	os.WriteFile("config.toml", []byte("from = \"news@example.com\"\nsendRate = 20\nworkers = 1\n[smtp]\nurl = \"file://out\"\n"), 0644)
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	root := cmd.New(config.BuildInfo{})
+	root.SetArgs([]string{"send", "c1", "all"})
+	root.SetOut(io.Discard)
+
+	errc := make(chan error, 1)
+	go func() { errc <- root.ExecuteContext(ctx) }()
+
+	// Interrupt once delivery is under way
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if files, _ := filepath.Glob("out/*.eml"); len(files) >= 2 {
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatalf("Delivery did not start")
+		}
+	}
+	syscall.Kill(os.Getpid(), syscall.SIGINT)
+
+	select {
+	case err := <-errc:
+		if !errors.Is(err, context.Canceled) || !errors.Is(err, send.ErrJobCancelled) {
+			t.Errorf("Expected cancelled send, got %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Send was not interrupted")
+	}
+
+	// Job was cancelled, rather than its queue stopped
+	jobs := send.Jobs()
+	if s := jobs[len(jobs)-1].State(); s != send.JobCancelled {
+		t.Errorf("Expected cancelled job, got %s", s)
+	}
+	if files, _ := filepath.Glob("out/*.eml"); len(files) >= 50 {
+		t.Errorf("Expected remaining emails to be dropped, got %d", len(files))
+	}
+}
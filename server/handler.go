@@ -33,6 +33,12 @@ type handler struct {
 }
 
 func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Subscriptions are served over WebSocket
+	if isWebSocket(r) {
+		h.serveWebSocket(w, r)
+		return
+	}
+
 	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
 		h.Handler.ServeHTTP(w, r)
 		return
@@ -134,6 +140,12 @@ func WithMiddleware(h http.Handler, cfg *config.AConfig) http.Handler {
 	if cfg != nil && cfg.ServerAuth != "" {
 		expU, expP, _ := strings.Cut(cfg.ServerAuth, ":")
 		n.UseFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+			// Recipients follow unsubscribe links without credentials
+			if strings.HasPrefix(r.URL.Path, UnsubscribePath) {
+				next(rw, r)
+				return
+			}
+
 			if u, p, ok := r.BasicAuth(); ok {
 				okU := subtle.ConstantTimeCompare([]byte(u), []byte(expU)) == 1
 				okP := subtle.ConstantTimeCompare([]byte(p), []byte(expP)) == 1
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"github.com/graph-gophers/graphql-go"
+	"github.com/rykov/paperboy/mail/send"
+
+	"context"
+	"fmt"
+	"time"
+)
+
+type JobArgs struct {
+	ID graphql.ID
+}
+
+// ===== Send jobs listing resolver ======
+
+func (r *Resolver) Jobs(ctx context.Context) []*sendJob {
+	jobs := []*sendJob{}
+	for _, j := range send.Jobs() {
+		jobs = append(jobs, &sendJob{j})
+	}
+	return jobs
+}
+
+// ===== Send job status resolver ======
+
+func (r *Resolver) Job(ctx context.Context, args JobArgs) *sendJob {
+	if j, ok := send.FindJob(string(args.ID)); ok {
+		return &sendJob{j}
+	}
+	return nil
+}
+
+// ===== Pause, resume, or cancel a running send ======
+
+func (r *Resolver) PauseSend(ctx context.Context, args JobArgs) (*sendJob, error) {
+	return controlJob(args.ID, (*send.Job).Pause)
+}
+
+func (r *Resolver) ResumeSend(ctx context.Context, args JobArgs) (*sendJob, error) {
+	return controlJob(args.ID, (*send.Job).Resume)
+}
+
+func (r *Resolver) CancelSend(ctx context.Context, args JobArgs) (*sendJob, error) {
+	return controlJob(args.ID, (*send.Job).Cancel)
+}
+
+func controlJob(id graphql.ID, action func(*send.Job) error) (*sendJob, error) {
+	j, ok := send.FindJob(string(id))
+	if !ok {
+		return nil, fmt.Errorf("send job %s not found", id)
+	} else if err := action(j); err != nil {
+		return nil, err
+	}
+	return &sendJob{j}, nil
+}
+
+type sendJob struct {
+	j *send.Job
+}
+
+func (j *sendJob) ID() graphql.ID {
+	return graphql.ID(j.j.ID)
+}
+
+func (j *sendJob) Campaign() string {
+	return j.j.Campaign
+}
+
+func (j *sendJob) List() string {
+	return j.j.List
+}
+
+func (j *sendJob) State() string {
+	return j.j.State()
+}
+
+func (j *sendJob) CreatedAt() string {
+	return j.j.Created.Format(time.RFC3339)
+}
+
+func (j *sendJob) StartedAt() *string {
+	return formatTime(j.j.Progress().Started)
+}
+
+func (j *sendJob) FinishedAt() *string {
+	return formatTime(j.j.Finished())
+}
+
+func (j *sendJob) Total() int32 {
+	return int32(j.j.Progress().Total)
+}
+
+func (j *sendJob) Queued() int32 {
+	return int32(j.j.Progress().Queued)
+}
+
+func (j *sendJob) Sent() int32 {
+	return int32(j.j.Progress().Sent)
+}
+
+func (j *sendJob) Failed() int32 {
+	return int32(j.j.Progress().Failed)
+}
+
+func (j *sendJob) Skipped() int32 {
+	return int32(j.j.Progress().Skipped)
+}
+
+func (j *sendJob) Remaining() int32 {
+	return int32(j.j.Progress().Remaining())
+}
+
+func (j *sendJob) ETA() *int32 {
+	if eta, ok := j.j.Progress().ETA(time.Now()); ok {
+		s := int32(eta.Round(time.Second).Seconds())
+		return &s
+	}
+	return nil
+}
+
+func (j *sendJob) Errors() []string {
+	return append([]string{}, j.j.Progress().Errors...)
+}
+
+func (j *sendJob) Error() *string {
+	if e := j.j.Progress().Error; e != "" {
+		return &e
+	}
+	return nil
+}
+
+func formatTime(t time.Time) *string {
+	if t.IsZero() {
+		return nil
+	}
+	s := t.Format(time.RFC3339)
+	return &s
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"github.com/rykov/paperboy/mail/send"
+
+	"encoding/json"
+	"testing"
+)
+
+func TestJobMutations(t *testing.T) {
+	cfg, _ := newTestConfigAndFs(t)
+	job := send.NewJob("c1", "list")
+
+	cases := []struct {
+		mutation string
+		state    string
+		err      bool
+	}{
+		{"pauseSend", send.JobPaused, false},
+		{"pauseSend", "", true},
+		{"resumeSend", send.JobRunning, false},
+		{"cancelSend", send.JobCancelled, false},
+		{"resumeSend", "", true},
+	}
+
+	for _, c := range cases {
+		response := issueGraphQL(cfg, `
+      mutation control($id: ID!) {
+        job: `+c.mutation+`(id: $id) { id campaign list state finishedAt }
+      }
+    `, map[string]interface{}{"id": job.ID})
+
+		if errs := response.Errors; c.err != (len(errs) > 0) {
+			t.Fatalf("%s: unexpected errors %+v", c.mutation, errs)
+		} else if c.err {
+			continue
+		}
+
+		data := struct {
+			Job struct {
+				ID, Campaign, List, State string
+				FinishedAt                *string
+			}
+		}{}
+		if err := json.Unmarshal(response.Data, &data); err != nil {
+			t.Fatalf("GraphQL data JSON error: %s", err)
+		}
+
+		if j := data.Job; j.ID != job.ID || j.Campaign != "c1" || j.List != "list" {
+			t.Errorf("%s: unexpected job %+v", c.mutation, j)
+		} else if j.State != c.state {
+			t.Errorf("%s: expected %s, got %s", c.mutation, c.state, j.State)
+		} else if j.FinishedAt != nil {
+			t.Errorf("%s: unexpected finishedAt %v", c.mutation, j.FinishedAt)
+		}
+	}
+
+	// Unknown job
+	response := issueGraphQL(cfg, `mutation { cancelSend(id: "missing") { id } }`, nil)
+	if len(response.Errors) == 0 {
+		t.Errorf("Expected error for unknown job")
+	}
+}
+
+func TestJobsQuery(t *testing.T) {
+	cfg, _ := newTestConfigAndFs(t)
+	job := send.NewJob("c2", "list")
+
+	response := issueGraphQLQuery(cfg, `{ jobs { id state } }`)
+	if errs := response.Errors; len(errs) > 0 {
+		t.Fatalf("GraphQL errors %+v", errs)
+	}
+
+	data := struct{ Jobs []struct{ ID, State string } }{}
+	if err := json.Unmarshal(response.Data, &data); err != nil {
+		t.Fatalf("GraphQL data JSON error: %s", err)
+	}
+
+	if n := len(data.Jobs); n == 0 || data.Jobs[n-1].ID != job.ID {
+		t.Fatalf("Expected job %s in %+v", job.ID, data.Jobs)
+	} else if s := data.Jobs[n-1].State; s != send.JobRunning {
+		t.Errorf("Expected running job, got %s", s)
+	}
+}
@@ -3,19 +3,26 @@ package server
 import (
 	"github.com/jordan-wright/email"
 	"github.com/rykov/paperboy/config"
-	"github.com/rykov/paperboy/mail"
 
 	"bytes"
 	"context"
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 // ===== ROOT QUERY RESOLVER ======
 
 type Resolver struct {
 	cfg *config.AConfig
+
+	// Project changes, watched on first subscription
+	// or on start of a live-reloading server
+	changes   changeFeed
+	previews  previewCache
+	watchOnce sync.Once
+	watchErr  error
 }
 
 func (r *Resolver) RenderOne(ctx context.Context, args *RenderOneArgs) (*renderedEmail, error) {
@@ -34,7 +41,7 @@ func (r *Resolver) RenderOne(ctx context.Context, args *RenderOneArgs) (*rendere
 	cfg := r.cfg.WithContext(ctx)
 
 	// Load campaign and recipient list
-	campaign, err := mail.LoadCampaign(cfg, args.Content, listID)
+	campaign, err := r.previews.load(cfg, args.Content, listID)
 	if err != nil {
 		return nil, err
 	} else if len(campaign.Recipients) == 0 {
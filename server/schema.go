@@ -8,6 +8,20 @@ import (
 )
 
 func GraphQLHandler(cfg *config.AConfig) http.Handler {
+	return graphQLHandler(&Resolver{cfg: cfg})
+}
+
+// LiveGraphQLHandler watches the project for changes to reload
+// previews, and to notify them via "contentChanged" subscription
+func LiveGraphQLHandler(cfg *config.AConfig) (http.Handler, error) {
+	r := &Resolver{cfg: cfg}
+	if err := r.watch(); err != nil {
+		return nil, err
+	}
+	return graphQLHandler(r), nil
+}
+
+func graphQLHandler(r *Resolver) http.Handler {
 	// CORS allows central preview
 	c := cors.New(cors.Options{
 		AllowedOrigins: []string{
@@ -18,7 +32,7 @@ func GraphQLHandler(cfg *config.AConfig) http.Handler {
 	})
 
 	// GraphQL handler for exposed API
-	handler := MustSchemaHandler(schemaText, &Resolver{cfg: cfg})
+	handler := MustSchemaHandler(schemaText, r)
 	return c.Handler(handler)
 }
 
@@ -26,6 +40,7 @@ const schemaText = `
   schema {
     query: Query
     mutation: Mutation
+    subscription: Subscription
   }
 
   # The Query type, represents all of the entry points
@@ -34,12 +49,23 @@ const schemaText = `
     lists: [RecipientList]!
     renderOne(content: String!, recipient: String!): RenderedEmail
     paperboyInfo: PaperboyInfo!
+    jobs: [SendJob!]!
+    job(id: ID!): SendJob
   }
 
   # All mutations
   type Mutation {
     sendBeta(content: String!, recipients: [RecipientInput!]!): Int!
-    sendCampaign(campaign: String!, list: String!): Boolean!
+    sendCampaign(campaign: String!, list: String!, at: String, window: String): ID!
+    pauseSend(id: ID!): SendJob!
+    resumeSend(id: ID!): SendJob!
+    cancelSend(id: ID!): SendJob!
+  }
+
+  # Live events, served over WebSocket
+  type Subscription {
+    deliveries(job: ID!): DeliveryEvent!
+    contentChanged: ContentEvent!
   }
 
   # A single rendered email information
@@ -68,6 +94,52 @@ const schemaText = `
     name: String!
   }
 
+  # Running or finished delivery
+  type SendJob {
+    id: ID!
+    campaign: String!
+    list: String!
+    state: String!
+    createdAt: String!
+    startedAt: String
+    finishedAt: String
+
+    # Delivery progress
+    total: Int!
+    queued: Int!
+    sent: Int!
+    failed: Int!
+    skipped: Int!
+    remaining: Int!
+    # Estimated seconds until finished
+    eta: Int
+
+    # Recent delivery errors, and job's outcome
+    errors: [String!]!
+    error: String
+  }
+
+  # Delivery attempt of a send job (sent, deferred, failed, or bounced)
+  type DeliveryEvent {
+    job: ID!
+    email: String!
+    messageId: String!
+    state: String!
+    code: Int
+    status: String
+    response: String
+    attempts: Int!
+    relay: String
+    time: String!
+  }
+
+  # Change of a file in content or layouts
+  type ContentEvent {
+    path: String!
+    op: String!
+    time: String!
+  }
+
   # Recipient metadata
   input RecipientInput {
     email: String!
@@ -1,8 +1,10 @@
 package server
 
 import (
+	"github.com/graph-gophers/graphql-go"
 	"github.com/rykov/paperboy/config"
 	"github.com/rykov/paperboy/mail"
+	"github.com/rykov/paperboy/mail/send"
 	"github.com/spf13/afero/zipfs"
 
 	"archive/zip"
@@ -73,39 +75,101 @@ func (r *Resolver) SendBeta(ctx context.Context, args SendOneArgs) (int32, error
 		return 0, err
 	}
 
-	// Populate recipients and fire away
+	// Populate recipients, without suppressed addresses
 	campaign.Recipients = recipients
+	if err := campaign.ExcludeSuppressed(cfg); err != nil {
+		return 0, err
+	} else if len(campaign.Recipients) == 0 {
+		return 0, fmt.Errorf("all recipients are suppressed")
+	}
+
+	// Fire away
 	err = mail.SendCampaign(cfg, campaign)
-	return int32(len(recipients)), err
+	return int32(len(campaign.Recipients)), err
 }
 
 type SendCampaignArgs struct {
 	Campaign string
 	List     string
+
+	// Scheduled delivery
+	At     *string
+	Window *string
 }
 
-// ===== Use ZIP-file attachment to deliver campaign to the recipient list ======
-func (r *Resolver) SendCampaign(ctx context.Context, args SendCampaignArgs) (bool, error) {
+// ===== Use ZIP-file attachment to start delivering campaign to the recipient list ======
+func (r *Resolver) SendCampaign(ctx context.Context, args SendCampaignArgs) (graphql.ID, error) {
 	file, ok := RequestZipFile(ctx)
 	if !ok {
-		return false, errors.New("ZIP: No file")
+		return "", errors.New("ZIP: No file")
 	}
+
+	// ZIP is read until delivery is finished
+	started := false
+	defer func() {
+		if !started {
+			file.Close()
+		}
+	}()
+
 	fi, err := file.Stat()
 	if err != nil {
-		return false, fmt.Errorf("ZIP: %w", err)
+		return "", fmt.Errorf("ZIP: %w", err)
 	}
 	zr, err := zip.NewReader(file, fi.Size())
 	if err != nil {
-		return false, fmt.Errorf("ZIP: %w", err)
+		return "", fmt.Errorf("ZIP: %w", err)
 	}
 
-	// Wrap incoming ZIP file into a virtual FS with context
-	cfg, err := config.LoadConfigFs(ctx, zipfs.New(zr))
+	// Wrap incoming ZIP file into a virtual FS, with server's
+	// context since the delivery outlives this request
+	cfg, err := config.LoadConfigFs(r.cfg.Context, zipfs.New(zr))
 	if err != nil {
-		return false, fmt.Errorf("ZIP Config: %w", err)
+		return "", fmt.Errorf("ZIP Config: %w", err)
+	}
+
+	// Local destinations (e.g. "file://out"), queue state and
+	// ledger are in server's project, since the upload is read-only
+	cfg.SMTP.Fs = r.cfg.AppFs
+	cfg.Delivery.Fs = r.cfg.AppFs
+
+	// Hold delivery until scheduled time and window
+	if args.At != nil && *args.At != "" {
+		cfg.Delivery.At = *args.At
 	}
+	if args.Window != nil && *args.Window != "" {
+		cfg.Delivery.Window = *args.Window
+	}
+	dc := cfg.Delivery
+	if _, err := send.ParseSchedule(dc.At, dc.Window, dc.Timezone); err != nil {
+		return "", err
+	}
+
+	// Load campaign and recipient list, before responding
+	campaign, err := mail.LoadCampaign(cfg, args.Campaign, args.List)
+	if err != nil {
+		return "", err
+	}
+
+	// Uploaded project may miss server's latest suppressions
+	if err := campaign.ExcludeSuppressed(r.cfg); err != nil {
+		return "", err
+	}
+
+	// Send it in the background 🚀 (see Job query)
+	job := send.NewJob(campaign.ID, campaign.ListID)
+	cfg = cfg.WithContext(send.WithJob(cfg.Context, job))
+	fmt.Printf("Starting send job %s\n", job.ID)
+
+	started = true
+	go func() {
+		defer file.Close()
+		err := mail.SendCampaign(cfg, campaign)
+		if err != nil {
+			fmt.Printf("Send job %s failed: %s\n", job.ID, err)
+		}
+		job.Done(err)
+	}()
 
-	// Load campaign and recipient list, and send it 🚀
-	err = mail.LoadAndSendCampaign(cfg, args.Campaign, args.List)
-	return err == nil, err
+	return graphql.ID(job.ID), nil
 }
@@ -3,12 +3,17 @@ package server
 import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/rykov/paperboy/mail"
+	"github.com/rykov/paperboy/mail/send"
 	"github.com/spf13/afero"
 
+	"archive/zip"
 	"bytes"
 	"encoding/json"
+	"net/http/httptest"
 	netmail "net/mail"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestSendMutation(t *testing.T) {
@@ -120,3 +125,125 @@ func TestSendMutation(t *testing.T) {
 		t.Fatalf("Unexpected delivery meta: %s", d)
 	}
 }
+
+func TestSendMutationSuppressed(t *testing.T) {
+	cfg, fs := newTestConfigAndFs(t)
+	cfg.DryRun = true
+	afero.WriteFile(fs, fs.ContentPath("c1.md"), []byte("# Hello"), 0644)
+	if _, err := mail.Suppress(cfg, mail.SuppressUnsubscribed, "test2@example.com", "test3@example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	query := `mutation send($recipients: [RecipientInput!]!) {
+		sendBeta(content: "c1", recipients: $recipients)
+	}`
+	recipients := func(emails ...string) map[string]interface{} {
+		out := []interface{}{}
+		for _, e := range emails {
+			out = append(out, map[string]interface{}{"email": e})
+		}
+		return map[string]interface{}{"recipients": out}
+	}
+
+	// Suppressed recipients are skipped
+	response := issueGraphQL(cfg, query, recipients("test1@example.com", "TEST2@example.com"))
+	if errs := response.Errors; len(errs) > 0 {
+		t.Fatalf("GraphQL errors %+v", errs)
+	} else if d := string(response.Data); d != `{"sendBeta":1}` {
+		t.Errorf("Expected one delivery, got %s", d)
+	}
+
+	// Nobody left to send to
+	response = issueGraphQL(cfg, query, recipients("test3@example.com"))
+	if errs := response.Errors; len(errs) != 1 || !strings.Contains(errs[0].Message, "suppressed") {
+		t.Errorf("Expected suppression error, got %+v", errs)
+	}
+}
+
+func TestSendCampaignMutation(t *testing.T) {
+	cfg, fs := newTestConfigAndFs(t)
+
+	// Project archive delivering into .eml files, with queue state and ledger
+	var zipData bytes.Buffer
+	zw := zip.NewWriter(&zipData)
+	for name, content := range map[string]string{
+		"config.toml": "from = \"sender@example.com\"\n[smtp]\nurl = \"file://out\"\n" +
+			"[delivery]\nqueue = \"disk\"\nledger = \"ledger.jsonl\"\n",
+		"content/c1.md": "# Hello",
+		"lists/r1.yaml": "- email: test1@example.com\n- email: test2@example.com\n",
+	} {
+		w, _ := zw.Create(name)
+		w.Write([]byte(content))
+	}
+	zw.Close()
+
+	// Respond with job ID right away
+	h := MustSchemaHandler(schemaText, &Resolver{cfg: cfg})
+	body, contentType, err := buildMultipartBody(map[string]any{
+		"query": `mutation { sendCampaign(campaign: "c1", list: "r1") }`,
+	}, zipData.Bytes())
+	if err != nil {
+		t.Fatalf("buildMultipartBody error: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/graphql", body)
+	req.Header.Set("Content-Type", contentType)
+	h.ServeHTTP(rr, req)
+
+	var resp struct {
+		Data   struct{ SendCampaign string }
+		Errors []map[string]any
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	} else if len(resp.Errors) > 0 || resp.Data.SendCampaign == "" {
+		t.Fatalf("Unexpected response: %s", rr.Body.String())
+	}
+
+	// Poll job until finished
+	type jobStatus struct {
+		State, StartedAt, FinishedAt   string
+		Total, Sent, Failed, Remaining int
+	}
+	var job jobStatus
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		response := issueGraphQL(cfg, `query job($id: ID!) {
+      job(id: $id) { state startedAt finishedAt total sent failed remaining }
+    }`, map[string]any{"id": resp.Data.SendCampaign})
+		if errs := response.Errors; len(errs) > 0 {
+			t.Fatalf("GraphQL errors %+v", errs)
+		}
+
+		data := struct{ Job jobStatus }{}
+		if err := json.Unmarshal(response.Data, &data); err != nil {
+			t.Fatalf("GraphQL data JSON error: %s", err)
+		} else if job = data.Job; job.State != send.JobRunning || time.Now().After(deadline) {
+			break
+		}
+	}
+
+	expected := jobStatus{State: send.JobDone, Total: 2, Sent: 2}
+	expected.StartedAt, expected.FinishedAt = job.StartedAt, job.FinishedAt
+	if d := cmp.Diff(expected, job); d != "" {
+		t.Errorf("Unexpected job status: %s", d)
+	} else if job.StartedAt == "" || job.FinishedAt == "" {
+		t.Errorf("Expected start and finish times: %+v", job)
+	}
+
+	// Delivered into server's project
+	if files, _ := afero.ReadDir(fs, "out"); len(files) != 2 {
+		t.Errorf("Expected 2 delivered emails, got %d", len(files))
+	}
+	for _, path := range []string{"ledger.jsonl", ".paperboy/queue/c1/r1.jsonl"} {
+		if ok, _ := afero.Exists(fs, path); !ok {
+			t.Errorf("Expected %s in server's project", path)
+		}
+	}
+
+	// Unknown job
+	response := issueGraphQLQuery(cfg, `{ job(id: "missing") { id } }`)
+	if string(response.Data) != `{"job":null}` {
+		t.Errorf("Expected no job, got %s", response.Data)
+	}
+}
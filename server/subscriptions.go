@@ -0,0 +1,104 @@
+package server
+
+import (
+	"github.com/graph-gophers/graphql-go"
+	"github.com/rykov/paperboy/mail/send"
+
+	"context"
+	"fmt"
+	"time"
+)
+
+type DeliveriesArgs struct {
+	Job graphql.ID
+}
+
+// ===== Delivery outcomes of a send job ======
+
+func (r *Resolver) Deliveries(ctx context.Context, args DeliveriesArgs) (<-chan *deliveryEvent, error) {
+	j, ok := send.FindJob(string(args.Job))
+	if !ok {
+		return nil, fmt.Errorf("send job %s not found", args.Job)
+	}
+
+	results := j.Subscribe(ctx)
+	events := make(chan *deliveryEvent)
+	go func() {
+		defer close(events)
+		for res := range results {
+			select {
+			case events <- &deliveryEvent{job: j.ID, r: res}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+type deliveryEvent struct {
+	job string
+	r   *send.Result
+}
+
+func (e *deliveryEvent) Job() graphql.ID {
+	return graphql.ID(e.job)
+}
+
+func (e *deliveryEvent) Email() string {
+	return e.r.Email
+}
+
+func (e *deliveryEvent) MessageID() string {
+	return e.r.MessageID
+}
+
+func (e *deliveryEvent) State() string {
+	return e.r.State
+}
+
+func (e *deliveryEvent) Code() *int32 {
+	if c := int32(e.r.Code); c != 0 {
+		return &c
+	}
+	return nil
+}
+
+func (e *deliveryEvent) Status() *string {
+	return optionalString(e.r.Status)
+}
+
+func (e *deliveryEvent) Response() *string {
+	return optionalString(e.r.Response)
+}
+
+func (e *deliveryEvent) Attempts() int32 {
+	return int32(e.r.Attempts)
+}
+
+func (e *deliveryEvent) Relay() *string {
+	return optionalString(e.r.Relay)
+}
+
+func (e *deliveryEvent) Time() string {
+	return e.r.Time.Format(time.RFC3339)
+}
+
+// ===== Changes of content and layouts ======
+
+func (r *Resolver) ContentChanged(ctx context.Context) (<-chan *contentEvent, error) {
+	// Start watching on first subscription
+	if err := r.watch(); err != nil {
+		return nil, err
+	}
+
+	return r.changes.subscribe(ctx), nil
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"github.com/rykov/paperboy/config"
+	"github.com/rykov/paperboy/mail"
+
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// UnsubscribePath serves links of unsubscribeURL with a "{token}"
+const UnsubscribePath = "/unsubscribe/"
+
+// UnsubscribeHandler confirms unsubscribe links, and suppresses the
+// address on POST, which also serves RFC 8058 one-click unsubscribes
+func UnsubscribeHandler(cfg *config.AConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.URL.Path, UnsubscribePath)
+		campaign, email, err := mail.ParseUnsubscribeToken(cfg.UnsubscribeSecret, token)
+		if err != nil {
+			renderUnsubscribe(w, http.StatusNotFound, &unsubscribePage{
+				Message: "This unsubscribe link is invalid.",
+			})
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			// Link scanners must not unsubscribe anyone
+			renderUnsubscribe(w, http.StatusOK, &unsubscribePage{
+				Message: fmt.Sprintf("Unsubscribe %s from all future emails?", email),
+				Confirm: true,
+			})
+		case http.MethodPost:
+			err := mail.UpdateSuppressions(cfg, func(s *mail.Suppressions) error {
+				s.Insert(mail.Suppression{
+					Email:    email,
+					Reason:   mail.SuppressUnsubscribed,
+					Time:     time.Now(),
+					Campaign: campaign,
+				})
+				return nil
+			})
+			if err != nil {
+				fmt.Printf("Could not unsubscribe %s: %s\n", email, err)
+				renderUnsubscribe(w, http.StatusInternalServerError, &unsubscribePage{
+					Message: "Something went wrong, please try again later.",
+				})
+				return
+			}
+
+			fmt.Printf("Unsubscribed %s (%s)\n", email, campaign)
+			renderUnsubscribe(w, http.StatusOK, &unsubscribePage{
+				Message: fmt.Sprintf("%s has been unsubscribed.", email),
+			})
+		default:
+			w.Header().Set("Allow", "GET, HEAD, POST")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+type unsubscribePage struct {
+	Message string
+	Confirm bool
+}
+
+var unsubscribeTemplate = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <meta name="robots" content="noindex">
+  <title>Unsubscribe</title>
+  <style>
+    body { font-family: sans-serif; max-width: 32em; margin: 4em auto; padding: 0 1em; text-align: center; }
+    button { font-size: 1em; padding: 0.5em 1.5em; cursor: pointer; }
+  </style>
+</head>
+<body>
+  <p>{{ .Message }}</p>
+  {{- if .Confirm }}
+  <form method="post">
+    <button type="submit">Unsubscribe</button>
+  </form>
+  {{- end }}
+</body>
+</html>
+`))
+
+func renderUnsubscribe(w http.ResponseWriter, status int, page *unsubscribePage) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	unsubscribeTemplate.Execute(w, page)
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"github.com/rykov/paperboy/mail"
+
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestUnsubscribeHandler(t *testing.T) {
+	cfg, _ := newTestConfigAndFs(t)
+	cfg.UnsubscribeSecret = "s3cret"
+	cfg.ServerAuth = "admin:pass"
+
+	mux := http.NewServeMux()
+	mux.Handle(UnsubscribePath, UnsubscribeHandler(cfg))
+	srv := httptest.NewServer(WithMiddleware(mux, cfg))
+	defer srv.Close()
+
+	link := srv.URL + UnsubscribePath + mail.UnsubscribeToken("s3cret", "newsletter", "jane@example.com")
+	suppressed := func() *mail.Suppression {
+		s, err := mail.LoadSuppressions(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e, _ := s.Find("jane@example.com")
+		return e
+	}
+
+	// Confirmation page, without authentication
+	resp, body := requestUnsubscribe(t, http.MethodGet, link, "")
+	if resp.StatusCode != http.StatusOK || !strings.Contains(body, "<form method=\"post\">") {
+		t.Fatalf("Unexpected confirmation %d: %s", resp.StatusCode, body)
+	} else if e := suppressed(); e != nil {
+		t.Fatalf("Unexpected suppression on GET: %+v", e)
+	}
+
+	// One-click unsubscribe (RFC 8058)
+	resp, body = requestUnsubscribe(t, http.MethodPost, link, "List-Unsubscribe=One-Click")
+	if resp.StatusCode != http.StatusOK || !strings.Contains(body, "has been unsubscribed") {
+		t.Fatalf("Unexpected unsubscribe %d: %s", resp.StatusCode, body)
+	}
+	if e := suppressed(); e == nil || e.Reason != mail.SuppressUnsubscribed || e.Campaign != "newsletter" {
+		t.Errorf("Unexpected suppression %+v", e)
+	}
+
+	// Repeated unsubscribe is fine
+	if resp, _ := requestUnsubscribe(t, http.MethodPost, link, ""); resp.StatusCode != http.StatusOK {
+		t.Errorf("Unexpected repeated unsubscribe %d", resp.StatusCode)
+	}
+
+	// Invalid tokens are rejected
+	forged := srv.URL + UnsubscribePath + mail.UnsubscribeToken("other", "newsletter", "john@example.com")
+	if resp, _ := requestUnsubscribe(t, http.MethodPost, forged, ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected forged token to be rejected, got %d", resp.StatusCode)
+	}
+
+	// The rest of the server still requires authentication
+	if resp, _ := requestUnsubscribe(t, http.MethodGet, srv.URL+"/graphql", ""); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected authentication, got %d", resp.StatusCode)
+	}
+}
+
+func requestUnsubscribe(t *testing.T, method, url, form string) (*http.Response, string) {
+	t.Helper()
+	req, _ := http.NewRequest(method, url, strings.NewReader(form))
+	if form != "" {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var body strings.Builder
+	if _, err := io.Copy(&body, resp.Body); err != nil {
+		t.Fatal(err)
+	}
+	return resp, body.String()
+}
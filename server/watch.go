@@ -0,0 +1,259 @@
+package server
+
+import (
+	"github.com/fsnotify/fsnotify"
+	"github.com/rykov/paperboy/config"
+	"github.com/rykov/paperboy/mail"
+
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Events buffered for each subscriber
+const changeBuffer = 10
+
+// Project file change, as pushed to subscribers
+type contentEvent struct {
+	path string
+	op   string
+	time time.Time
+}
+
+func (e *contentEvent) Path() string {
+	return e.path
+}
+
+func (e *contentEvent) Op() string {
+	return e.op
+}
+
+func (e *contentEvent) Time() string {
+	return e.time.Format(time.RFC3339)
+}
+
+// watch starts watching the project on first call, and then
+// invalidates previews and notifies subscribers of each change
+func (r *Resolver) watch() error {
+	r.watchOnce.Do(func() {
+		dirs := r.cfg.AppFs.ProjectDirs()
+		r.watchErr = watchProject(r.cfg.Context, r.cfg.AppFs, dirs, func(e *contentEvent) {
+			fmt.Printf("Change detected (%s %s), reloading\n", e.op, e.path)
+			r.previews.reset()
+			r.changes.publish(e)
+		})
+		if r.watchErr == nil {
+			r.previews.enable()
+		}
+	})
+	return r.watchErr
+}
+
+// previewCache keeps loaded campaigns for rendering previews,
+// which is only enabled while watching for project changes
+type previewCache struct {
+	campaigns  map[[2]string]*mail.Campaign
+	enabled    bool
+	generation int // Incremented by reset
+	lock       sync.Mutex
+}
+
+// load campaign from cache, or project, with cfg of the request
+func (p *previewCache) load(cfg *config.AConfig, content, list string) (*mail.Campaign, error) {
+	p.lock.Lock()
+	enabled, key := p.enabled, [2]string{content, list}
+	c, ok := p.campaigns[key]
+	gen := p.generation
+	p.lock.Unlock()
+
+	if !enabled {
+		return mail.LoadCampaign(cfg, content, list)
+	} else if !ok {
+		var err error
+		if c, err = mail.LoadCampaign(cfg, content, list); err != nil {
+			return nil, err
+		}
+
+		// Stale, if project changed while loading
+		p.lock.Lock()
+		if p.enabled && p.generation == gen {
+			p.campaigns[key] = c
+		}
+		p.lock.Unlock()
+	}
+
+	out := *c
+	out.Config = cfg
+	return &out, nil
+}
+
+func (p *previewCache) enable() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.campaigns = map[[2]string]*mail.Campaign{}
+	p.enabled = true
+}
+
+func (p *previewCache) reset() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	clear(p.campaigns)
+	p.generation++
+}
+
+// changeFeed broadcasts project file changes to subscribers
+type changeFeed struct {
+	subs map[chan *contentEvent]struct{}
+	lock sync.Mutex
+}
+
+// subscribe streams changes until ctx is done, dropping
+// them while subscriber's buffer is full
+func (f *changeFeed) subscribe(ctx context.Context) <-chan *contentEvent {
+	ch := make(chan *contentEvent, changeBuffer)
+
+	f.lock.Lock()
+	if f.subs == nil {
+		f.subs = map[chan *contentEvent]struct{}{}
+	}
+	f.subs[ch] = struct{}{}
+	f.lock.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		f.lock.Lock()
+		defer f.lock.Unlock()
+		delete(f.subs, ch)
+		close(ch)
+	}()
+
+	return ch
+}
+
+func (f *changeFeed) publish(e *contentEvent) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for ch := range f.subs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// watchProject publishes changes of files in project's dirs
+// (and their subdirectories) until ctx is done
+func watchProject(ctx context.Context, afs *config.Fs, dirs []string, publish func(*contentEvent)) error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	// Real location of each project dir
+	roots := map[string]string{}
+	for _, dir := range dirs {
+		root, ok := afs.RealPath(dir)
+		if !ok {
+			w.Close()
+			return fmt.Errorf("cannot watch %s outside of local filesystem", dir)
+		} else if err := watchTree(w, root); err != nil {
+			w.Close()
+			return err
+		} else if _, err := os.Stat(root); os.IsNotExist(err) {
+			// Watch parent for the dir to be created
+			w.Add(filepath.Dir(root))
+		}
+		roots[root] = dir
+	}
+
+	// Path of changed file within the project,
+	// unless it is outside of project's dirs
+	projectPath := func(name string) (string, bool) {
+		for root, dir := range roots {
+			if rel, err := filepath.Rel(root, name); err == nil && !strings.HasPrefix(rel, "..") {
+				return filepath.Join(dir, rel), true
+			}
+		}
+		return "", false
+	}
+
+	go func() {
+		defer w.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case err, ok := <-w.Errors:
+				if !ok {
+					return
+				}
+				fmt.Printf("Watching project failed: %s\n", err)
+			case ev, ok := <-w.Events:
+				if !ok {
+					return
+				}
+
+				path, ok := projectPath(ev.Name)
+				if !ok || isTempFile(ev.Name) {
+					continue
+				}
+
+				// Start watching new directories
+				if ev.Has(fsnotify.Create) {
+					if fi, err := os.Stat(ev.Name); err == nil && fi.IsDir() {
+						watchTree(w, ev.Name)
+					}
+				}
+
+				if op := eventOp(ev); op != "" {
+					publish(&contentEvent{path: path, op: op, time: time.Now()})
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// watchTree watches dir and its subdirectories, if it exists
+func watchTree(w *fsnotify.Watcher, dir string) error {
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		} else if d.IsDir() {
+			return w.Add(path)
+		}
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// Change type, ignoring changes of attributes
+func eventOp(ev fsnotify.Event) string {
+	switch {
+	case ev.Has(fsnotify.Create):
+		return "create"
+	case ev.Has(fsnotify.Write):
+		return "write"
+	case ev.Has(fsnotify.Remove):
+		return "remove"
+	case ev.Has(fsnotify.Rename):
+		return "rename"
+	}
+	return ""
+}
+
+// Editors' swap and backup files (e.g. ".file.swp" or "file~")
+func isTempFile(name string) bool {
+	base := filepath.Base(name)
+	return strings.HasPrefix(base, ".") || strings.HasPrefix(base, "#") ||
+		strings.HasSuffix(base, "~") || strings.HasSuffix(base, ".swp")
+}
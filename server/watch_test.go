@@ -0,0 +1,174 @@
+package server
+
+import (
+	"github.com/graph-gophers/graphql-go"
+	"github.com/rykov/paperboy/config"
+	"github.com/spf13/afero"
+
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLiveReloadPreview(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "content"), 0755)
+	os.MkdirAll(filepath.Join(dir, "lists"), 0755)
+	os.WriteFile(filepath.Join(dir, "content", "c1.md"), []byte("# Hello"), 0644)
+	os.WriteFile(filepath.Join(dir, "lists", "r1.yaml"), []byte("- email: ex@example.org\n"), 0644)
+
+	cfg, err := config.LoadConfigFs(t.Context(), afero.NewBasePathFs(afero.NewOsFs(), dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.From = "sender@example.com"
+
+	r := &Resolver{cfg: cfg}
+	if err := r.watch(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Campaigns are reused while unchanged
+	c1, err1 := r.previews.load(cfg, "c1", "r1")
+	c2, err2 := r.previews.load(cfg, "c1", "r1")
+	if err1 != nil || err2 != nil {
+		t.Fatalf("Load failed: %v, %v", err1, err2)
+	} else if c1.Recipients[0] != c2.Recipients[0] {
+		t.Errorf("Expected cached campaign")
+	}
+
+	// Changes invalidate cached campaigns
+	schema := graphql.MustParseSchema(schemaText, r)
+	os.WriteFile(filepath.Join(dir, "content", "c1.md"), []byte("# Changed"), 0644)
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		resp := schema.Exec(cfg.Context, `{ renderOne(content: "c1", recipient: "r1#0") { html } }`, "", nil)
+		if len(resp.Errors) > 0 {
+			t.Fatalf("GraphQL errors %+v", resp.Errors)
+		}
+
+		var out struct{ RenderOne struct{ HTML string } }
+		json.Unmarshal(resp.Data, &out)
+		if strings.Contains(out.RenderOne.HTML, "Changed") {
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatalf("Preview was not reloaded: %s", out.RenderOne.HTML)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWatchProject(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "content"), 0755)
+
+	cfg, err := config.LoadConfigFs(t.Context(), afero.NewBasePathFs(afero.NewOsFs(), dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := make(chan *contentEvent, 10)
+	err = watchProject(t.Context(), cfg.AppFs, []string{"content", "layouts"}, func(e *contentEvent) {
+		events <- e
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	next := func() string {
+		select {
+		case e := <-events:
+			return e.op + " " + e.path
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for change")
+			return ""
+		}
+	}
+
+	// Swap files and files outside of project dirs are ignored
+	os.WriteFile(filepath.Join(dir, "content", ".c1.md.swp"), nil, 0644)
+	os.WriteFile(filepath.Join(dir, "config.toml"), nil, 0644)
+	os.WriteFile(filepath.Join(dir, "content", "c1.md"), nil, 0644)
+	if e := next(); e != "create content/c1.md" {
+		t.Errorf("Unexpected change %q", e)
+	}
+
+	// Missing dirs are watched once created
+	os.Mkdir(filepath.Join(dir, "layouts"), 0755)
+	if e := next(); e != "create layouts" {
+		t.Fatalf("Unexpected change %q", e)
+	}
+	os.WriteFile(filepath.Join(dir, "layouts", "_default.html"), nil, 0644)
+	if e := next(); e != "create layouts/_default.html" {
+		t.Errorf("Unexpected change %q", e)
+	}
+}
+
+func TestPreviewCacheDisabled(t *testing.T) {
+	cfg, fs := newTestConfigAndFs(t)
+	afero.WriteFile(fs, fs.ContentPath("c1.md"), []byte("# Hello"), 0644)
+	afero.WriteFile(fs, fs.ListPath("r1.yaml"), []byte("- email: ex@example.org\n"), 0644)
+
+	// Without watching, campaigns are always loaded
+	var p previewCache
+	c1, _ := p.load(cfg, "c1", "r1")
+	c2, _ := p.load(cfg, "c1", "r1")
+	if c1 == nil || c2 == nil || c1.Recipients[0] == c2.Recipients[0] {
+		t.Errorf("Expected campaigns to be reloaded")
+	}
+}
+
+func TestPreviewCacheResetWhileLoading(t *testing.T) {
+	var p previewCache
+	p.enable()
+
+	// Project changes while the campaign is loading
+	memFs := afero.NewMemMapFs()
+	afero.WriteFile(memFs, "content/c1.md", []byte("# Hello"), 0644)
+	afero.WriteFile(memFs, "lists/r1.yaml", []byte("- email: ex@example.org\n"), 0644)
+	cfg, err := config.LoadConfigFs(t.Context(), &resetOnOpenFs{memFs, &p})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.From = "sender@example.com"
+
+	if _, err := p.load(cfg, "c1", "r1"); err != nil {
+		t.Fatal(err)
+	} else if len(p.campaigns) != 0 {
+		t.Errorf("Campaign loaded before reset should not be cached")
+	}
+}
+
+// Fs that resets the preview cache when content is opened
+type resetOnOpenFs struct {
+	afero.Fs
+	cache *previewCache
+}
+
+func (fs *resetOnOpenFs) Open(name string) (afero.File, error) {
+	if strings.HasPrefix(filepath.ToSlash(name), "content/") {
+		fs.cache.reset()
+	}
+	return fs.Fs.Open(name)
+}
+
+func TestIsTempFile(t *testing.T) {
+	cases := map[string]bool{
+		"content/c1.md":       false,
+		"lists/_suppressed":   false,
+		"content/.c1.md.swp":  true,
+		"content/c1.md.swp":   true,
+		"content/c1.md~":      true,
+		"content/#c1.md#":     true,
+		"layouts/.DS_Store":   true,
+		"layouts/_default.md": false,
+	}
+
+	for name, expected := range cases {
+		if v := isTempFile(name); v != expected {
+			t.Errorf("isTempFile(%q) = %v, expected %v", name, v, expected)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package server
+
+import (
+	"github.com/graph-gophers/graphql-go"
+	"golang.org/x/net/websocket"
+
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Subprotocol of GraphQL over WebSocket (see github.com/enisdenjo/graphql-ws)
+const wsProtocol = "graphql-transport-ws"
+
+// Message types of wsProtocol
+const (
+	wsConnectionInit = "connection_init"
+	wsConnectionAck  = "connection_ack"
+	wsPing           = "ping"
+	wsPong           = "pong"
+	wsSubscribe      = "subscribe"
+	wsNext           = "next"
+	wsError          = "error"
+	wsComplete       = "complete"
+)
+
+type wsMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// Is request an upgrade to WebSocket
+func isWebSocket(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket")
+}
+
+// serveWebSocket executes subscriptions, and other operations,
+// sent over a WebSocket connection
+func (h *handler) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	s := websocket.Server{
+		Handshake: func(cfg *websocket.Config, r *http.Request) error {
+			if !slices.Contains(cfg.Protocol, wsProtocol) {
+				return fmt.Errorf("unsupported subprotocol %q", cfg.Protocol)
+			}
+			cfg.Protocol = []string{wsProtocol}
+
+			// Browsers may only connect from the same host
+			if o := r.Header.Get("Origin"); o != "" {
+				if u, err := url.Parse(o); err != nil || u.Host != r.Host {
+					return fmt.Errorf("cross-origin WebSocket from %s", o)
+				}
+			}
+			return nil
+		},
+		Handler: func(ws *websocket.Conn) {
+			c := &wsConn{ws: ws, schema: h.Schema, ops: map[string]context.CancelFunc{}}
+			c.serve(r.Context())
+		},
+	}
+	s.ServeHTTP(w, r)
+}
+
+// wsConn is a single client's connection
+type wsConn struct {
+	ws     *websocket.Conn
+	schema *graphql.Schema
+	writeL sync.Mutex
+
+	// Running operations by ID
+	ops  map[string]context.CancelFunc
+	opsL sync.Mutex
+}
+
+func (c *wsConn) serve(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	initialized := false
+	for {
+		var msg wsMessage
+		if err := websocket.JSON.Receive(c.ws, &msg); err != nil {
+			return // Closed
+		}
+
+		switch msg.Type {
+		case wsConnectionInit:
+			initialized = true
+			c.send(&wsMessage{Type: wsConnectionAck})
+		case wsPing:
+			c.send(&wsMessage{Type: wsPong})
+		case wsPong:
+		case wsSubscribe:
+			if !initialized || msg.ID == "" {
+				return // Protocol violation
+			} else if err := c.start(ctx, msg.ID, msg.Payload); err != nil {
+				return
+			}
+		case wsComplete:
+			c.stop(msg.ID)
+		default:
+			return // Unknown message
+		}
+	}
+}
+
+// start executes an operation until it completes, or is stopped
+func (c *wsConn) start(ctx context.Context, id string, payload json.RawMessage) error {
+	var params gqlRequestParams
+	if err := json.Unmarshal(payload, &params); err != nil {
+		return err
+	}
+
+	c.opsL.Lock()
+	if _, ok := c.ops[id]; ok {
+		c.opsL.Unlock()
+		return errors.New("duplicate operation ID")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	c.ops[id] = cancel
+	c.opsL.Unlock()
+
+	responses, err := c.schema.Subscribe(ctx, params.Query, params.OperationName, params.Variables)
+	if err != nil {
+		cancel()
+		return err
+	}
+
+	go func() {
+		defer c.stop(id)
+		for resp := range responses {
+			r, ok := resp.(*graphql.Response)
+			if !ok {
+				continue
+			}
+
+			// Operation failed before execution (e.g. validation)
+			if r.Data == nil && len(r.Errors) > 0 {
+				errs, _ := json.Marshal(r.Errors)
+				c.send(&wsMessage{ID: id, Type: wsError, Payload: errs})
+				return
+			}
+
+			data, _ := json.Marshal(r)
+			c.send(&wsMessage{ID: id, Type: wsNext, Payload: data})
+		}
+
+		if ctx.Err() == nil {
+			c.send(&wsMessage{ID: id, Type: wsComplete})
+		}
+	}()
+
+	return nil
+}
+
+// stop cancels a running operation
+func (c *wsConn) stop(id string) {
+	c.opsL.Lock()
+	defer c.opsL.Unlock()
+	if cancel, ok := c.ops[id]; ok {
+		delete(c.ops, id)
+		cancel()
+	}
+}
+
+func (c *wsConn) send(msg *wsMessage) {
+	c.writeL.Lock()
+	defer c.writeL.Unlock()
+	websocket.JSON.Send(c.ws, msg)
+}
@@ -0,0 +1,180 @@
+package server
+
+import (
+	"github.com/rykov/paperboy/config"
+	"github.com/rykov/paperboy/mail/send"
+	"github.com/spf13/afero"
+	"golang.org/x/net/websocket"
+
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebSocketProtocol(t *testing.T) {
+	cfg, _ := newTestConfigAndFs(t)
+	ws := dialTestWebSocket(t, cfg)
+
+	sendWS(t, ws, wsMessage{Type: wsPing})
+	if m := receiveWS(t, ws); m.Type != wsPong {
+		t.Fatalf("Expected pong, got %+v", m)
+	}
+
+	// Queries are executed once
+	subscribeWS(t, ws, "q1", `{ paperboyInfo { version } }`, nil)
+	if m := receiveWS(t, ws); m.Type != wsNext || m.ID != "q1" || !strings.Contains(string(m.Payload), "version") {
+		t.Fatalf("Expected query result, got %+v", m)
+	} else if m := receiveWS(t, ws); m.Type != wsComplete || m.ID != "q1" {
+		t.Fatalf("Expected complete, got %+v", m)
+	}
+
+	// Invalid operations fail
+	subscribeWS(t, ws, "q2", `subscription { missing }`, nil)
+	if m := receiveWS(t, ws); m.Type != wsError || m.ID != "q2" {
+		t.Fatalf("Expected error, got %+v", m)
+	}
+}
+
+func TestWebSocketOrigin(t *testing.T) {
+	cfg, _ := newTestConfigAndFs(t)
+	srv := httptest.NewServer(WithMiddleware(MustSchemaHandler(schemaText, &Resolver{cfg: cfg}), nil))
+	defer srv.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http")
+	wc, _ := websocket.NewConfig(wsURL, "http://evil.example.com")
+	wc.Protocol = []string{wsProtocol}
+	if ws, err := websocket.DialConfig(wc); err == nil {
+		ws.Close()
+		t.Fatalf("Expected cross-origin WebSocket to be rejected")
+	}
+}
+
+func TestDeliveriesSubscription(t *testing.T) {
+	cfg, _ := newTestConfigAndFs(t)
+	ws := dialTestWebSocket(t, cfg)
+	job := send.NewJob("c1", "list")
+
+	subscribeWS(t, ws, "s1", `subscription deliveries($job: ID!) {
+    deliveries(job: $job) { job email state code }
+  }`, map[string]any{"job": job.ID})
+
+	// Report until subscription is running
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case <-time.After(10 * time.Millisecond):
+				job.Report(&send.Result{Email: "a@example.com", State: send.StateSent, Code: 250})
+			}
+		}
+	}()
+
+	m := receiveWS(t, ws)
+	expected := `{"data":{"deliveries":{"job":"` + job.ID + `","email":"a@example.com","state":"sent","code":250}}}`
+	if m.Type != wsNext || string(m.Payload) != expected {
+		t.Fatalf("Unexpected delivery event %+v", m)
+	}
+
+	// Finished job completes the subscription
+	job.Done(nil)
+	for m.Type == wsNext {
+		m = receiveWS(t, ws)
+	}
+	if m.Type != wsComplete || m.ID != "s1" {
+		t.Fatalf("Expected complete, got %+v", m)
+	}
+}
+
+func TestContentChangedSubscription(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "content"), 0755)
+	cfg, err := config.LoadConfigFs(t.Context(), afero.NewBasePathFs(afero.NewOsFs(), dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ws := dialTestWebSocket(t, cfg)
+	subscribeWS(t, ws, "s1", `subscription { contentChanged { path op } }`, nil)
+
+	// Write until subscription is watching
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		path := filepath.Join(dir, "content", "c1.md")
+		for {
+			select {
+			case <-stop:
+				return
+			case <-time.After(10 * time.Millisecond):
+				os.WriteFile(path, []byte("# Hello"), 0644)
+			}
+		}
+	}()
+
+	m := receiveWS(t, ws)
+	var resp struct {
+		Data struct{ ContentChanged struct{ Path, Op string } }
+	}
+	if err := json.Unmarshal(m.Payload, &resp); err != nil || m.Type != wsNext {
+		t.Fatalf("Unexpected message %+v", m)
+	} else if c := resp.Data.ContentChanged; c.Path != "content/c1.md" || (c.Op != "create" && c.Op != "write") {
+		t.Errorf("Unexpected change %+v", c)
+	}
+
+	// Unsubscribe
+	sendWS(t, ws, wsMessage{ID: "s1", Type: wsComplete})
+}
+
+// Connection with initialized protocol
+func dialTestWebSocket(t *testing.T, cfg *config.AConfig) *websocket.Conn {
+	t.Helper()
+	srv := httptest.NewServer(WithMiddleware(MustSchemaHandler(schemaText, &Resolver{cfg: cfg}), nil))
+	t.Cleanup(srv.Close)
+
+	wc, err := websocket.NewConfig("ws"+strings.TrimPrefix(srv.URL, "http"), srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wc.Protocol = []string{wsProtocol}
+	ws, err := websocket.DialConfig(wc)
+	if err != nil {
+		t.Fatalf("WebSocket dial failed: %v", err)
+	}
+	t.Cleanup(func() { ws.Close() })
+
+	sendWS(t, ws, wsMessage{Type: wsConnectionInit})
+	if m := receiveWS(t, ws); m.Type != wsConnectionAck {
+		t.Fatalf("Expected connection_ack, got %+v", m)
+	}
+	return ws
+}
+
+func subscribeWS(t *testing.T, ws *websocket.Conn, id, query string, vars map[string]any) {
+	t.Helper()
+	payload, _ := json.Marshal(map[string]any{"query": query, "variables": vars})
+	sendWS(t, ws, wsMessage{ID: id, Type: wsSubscribe, Payload: payload})
+}
+
+func sendWS(t *testing.T, ws *websocket.Conn, m wsMessage) {
+	t.Helper()
+	if err := websocket.JSON.Send(ws, m); err != nil {
+		t.Fatalf("WebSocket send failed: %v", err)
+	}
+}
+
+func receiveWS(t *testing.T, ws *websocket.Conn) wsMessage {
+	t.Helper()
+	var m wsMessage
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if err := websocket.JSON.Receive(ws, &m); err != nil {
+		t.Fatalf("WebSocket receive failed: %v", err)
+	}
+	return m
+}
@@ -1,9 +1,14 @@
 import query from 'preview/gql/queries/preview.graphql';
+import contentChanged from 'preview/gql/subscriptions/content-changed.graphql';
 import { queryManager } from 'ember-apollo-client';
 import Route from '@ember/routing/route';
+import { service } from '@ember/service';
 
 export default class PreviewRenderRoute extends Route {
   @queryManager apollo;
+  @service live;
+
+  _unsubscribe = null;
 
   model(params) {
     let c = params.content_id;
@@ -17,4 +22,16 @@ export default class PreviewRenderRoute extends Route {
       'renderOne',
     );
   }
+
+  // Re-render when content or layouts change
+  activate() {
+    super.activate(...arguments);
+    this._unsubscribe = this.live.subscribe(contentChanged, {}, () => this.refresh());
+  }
+
+  deactivate() {
+    super.deactivate(...arguments);
+    this._unsubscribe?.();
+    this._unsubscribe = null;
+  }
 }
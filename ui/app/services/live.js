@@ -0,0 +1,104 @@
+import Service from '@ember/service';
+import config from 'preview/config/environment';
+import { print } from 'graphql';
+
+// Subprotocol of GraphQL over WebSocket (see github.com/enisdenjo/graphql-ws)
+const PROTOCOL = 'graphql-transport-ws';
+
+// Delay before reconnecting a dropped connection
+const RECONNECT_DELAY = 2000;
+
+// Minimal client for GraphQL subscriptions served by Paperboy
+export default class LiveService extends Service {
+  _socket = null;
+  _ready = false;
+  _nextId = 1;
+  _subscriptions = new Map();
+
+  // Calls onNext with data of every event, until unsubscribed
+  subscribe(query, variables, onNext) {
+    const id = String(this._nextId++);
+    const sub = { query: print(query), variables, onNext };
+    this._subscriptions.set(id, sub);
+    this._connect();
+    if (this._ready) {
+      this._start(id, sub);
+    }
+
+    return () => {
+      this._subscriptions.delete(id);
+      this._send({ id, type: 'complete' });
+      if (this._subscriptions.size === 0) {
+        this._close();
+      }
+    };
+  }
+
+  willDestroy() {
+    super.willDestroy(...arguments);
+    this._subscriptions.clear();
+    this._close();
+  }
+
+  _connect() {
+    if (this._socket) {
+      return;
+    }
+
+    const url = new URL(config.apollo.apiURL, window.location.href);
+    url.protocol = url.protocol === 'https:' ? 'wss:' : 'ws:';
+
+    const socket = new WebSocket(url, PROTOCOL);
+    socket.onopen = () => this._send({ type: 'connection_init' });
+    socket.onmessage = (e) => this._receive(JSON.parse(e.data));
+    socket.onclose = () => {
+      if (this._socket !== socket) {
+        return; // Closed intentionally
+      }
+      this._socket = null;
+      this._ready = false;
+      setTimeout(() => {
+        if (this._subscriptions.size > 0) {
+          this._connect();
+        }
+      }, RECONNECT_DELAY);
+    };
+    this._socket = socket;
+  }
+
+  _close() {
+    const socket = this._socket;
+    this._socket = null;
+    this._ready = false;
+    socket?.close();
+  }
+
+  _receive(msg) {
+    switch (msg.type) {
+      case 'connection_ack':
+        this._ready = true;
+        this._subscriptions.forEach((sub, id) => this._start(id, sub));
+        break;
+      case 'ping':
+        this._send({ type: 'pong' });
+        break;
+      case 'next':
+        this._subscriptions.get(msg.id)?.onNext(msg.payload.data);
+        break;
+      case 'error':
+        console.error('Subscription failed', msg.payload);
+        this._subscriptions.delete(msg.id);
+        break;
+    }
+  }
+
+  _start(id, { query, variables }) {
+    this._send({ id, type: 'subscribe', payload: { query, variables } });
+  }
+
+  _send(msg) {
+    if (this._socket?.readyState === WebSocket.OPEN) {
+      this._socket.send(JSON.stringify(msg));
+    }
+  }
+}